@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/qntfy/jsonparser"
 	"github.com/qntfy/kazaam/v4/transform"
@@ -23,19 +25,82 @@ import (
 // Transforms should strive to fail gracefully whenever possible.
 type TransformFunc func(spec *transform.Config, data []byte) ([]byte, error)
 
+// PrepareFunc defines the contract for the optional load-time step of a transform.
+// It is called once by `New` for every spec using the transform, before any data is
+// transformed, and should parse and cache whatever part of the `transform.Config`
+// does not depend on the data, such as compiled regular expressions. An error
+// returned by the function is reported as a SpecError.
+type PrepareFunc func(spec *transform.Config) error
+
 var validSpecTypes map[string]TransformFunc
+var validSpecPreparers map[string]PrepareFunc
 
 func init() {
 	validSpecTypes = map[string]TransformFunc{
-		"pass":      transform.Pass,
-		"shift":     transform.Shift,
-		"extract":   transform.Extract,
-		"default":   transform.Default,
-		"delete":    transform.Delete,
-		"concat":    transform.Concat,
-		"coalesce":  transform.Coalesce,
-		"timestamp": transform.Timestamp,
-		"uuid":      transform.UUID,
+		"pass":       transform.Pass,
+		"shift":      transform.Shift,
+		"extract":    transform.Extract,
+		"default":    transform.Default,
+		"delete":     transform.Delete,
+		"concat":     transform.Concat,
+		"coalesce":   transform.Coalesce,
+		"timestamp":  transform.Timestamp,
+		"uuid":       transform.UUID,
+		"cast":       transform.Cast,
+		"merge":      transform.Merge,
+		"split":      transform.Split,
+		"regex":      transform.Regex,
+		"case":       transform.Case,
+		"renameKeys": transform.RenameKeys,
+		"math":       transform.Math,
+		"expr":       transform.Expr,
+		"filter":     transform.Filter,
+		"sort":       transform.Sort,
+		"unique":     transform.Unique,
+		"keyBy":      transform.KeyBy,
+		"groupBy":    transform.GroupBy,
+		"entries":    transform.Entries,
+		"toArray":    transform.Entries,
+		"flatten":    transform.Flatten,
+		"unflatten":  transform.Unflatten,
+		"aggregate":  transform.Aggregate,
+		"hash":       transform.Hash,
+		"mask":       transform.Mask,
+		"encode":     transform.Encode,
+		"decode":     transform.Decode,
+		"template":   transform.Template,
+		"lookup":     transform.Lookup,
+		"string":     transform.String,
+		"datetime":   transform.Datetime,
+	}
+	validSpecPreparers = map[string]PrepareFunc{
+		"default":    transform.PrepareDefault,
+		"split":      transform.PrepareSplit,
+		"regex":      transform.PrepareRegex,
+		"renameKeys": transform.PrepareRenameKeys,
+		"math":       transform.PrepareMath,
+		"expr":       transform.PrepareExpr,
+		"filter":     transform.PrepareFilter,
+		"sort":       transform.PrepareSort,
+		"unique":     transform.PrepareUnique,
+		"keyBy":      transform.PrepareKeyBy,
+		"groupBy":    transform.PrepareGroupBy,
+		"entries":    transform.PrepareEntries,
+		"toArray":    transform.PrepareEntries,
+		"flatten":    transform.PrepareFlatten,
+		"unflatten":  transform.PrepareUnflatten,
+		"aggregate":  transform.PrepareAggregate,
+		"hash":       transform.PrepareHash,
+		"mask":       transform.PrepareMask,
+		"encode":     transform.PrepareEncode,
+		"decode":     transform.PrepareDecode,
+		"template":   transform.PrepareTemplate,
+		"lookup":     transform.PrepareLookup,
+		"string":     transform.PrepareString,
+		"timestamp":  transform.PrepareTimestamp,
+		"datetime":   transform.PrepareDatetime,
+		"cast":       transform.PrepareCast,
+		"case":       transform.PrepareCase,
 	}
 }
 
@@ -72,7 +137,12 @@ func (e *Error) Error() string {
 // Kazaam transforms. Built-in and third-party Kazaam transforms will have to be
 // manually registered for Kazaam to be able to transform data.
 type Config struct {
-	transforms map[string]TransformFunc
+	transforms   map[string]TransformFunc
+	preparers    map[string]PrepareFunc
+	hashKeys     map[string][]byte
+	lookupTables map[string]map[string]interface{}
+	clock        func() time.Time
+	rand         io.Reader
 }
 
 // NewDefaultConfig returns a properly initialized Config object that contains
@@ -83,7 +153,16 @@ func NewDefaultConfig() Config {
 	for k, v := range validSpecTypes {
 		specTypes[k] = v
 	}
-	return Config{transforms: specTypes}
+	specPreparers := make(map[string]PrepareFunc)
+	for k, v := range validSpecPreparers {
+		specPreparers[k] = v
+	}
+	return Config{
+		transforms:   specTypes,
+		preparers:    specPreparers,
+		hashKeys:     make(map[string][]byte),
+		lookupTables: make(map[string]map[string]interface{}),
+	}
 }
 
 // RegisterTransform registers a new transform type that satisfies the TransformFunc
@@ -98,6 +177,67 @@ func (c *Config) RegisterTransform(name string, function TransformFunc) error {
 	return nil
 }
 
+// RegisterTransformWithPrepare registers a new transform type like RegisterTransform,
+// along with a PrepareFunc called by `New` for every spec using the transform. This
+// allows custom transforms to reject invalid specs when the Kazaam object is created,
+// rather than when transforming data.
+func (c *Config) RegisterTransformWithPrepare(name string, function TransformFunc, prepare PrepareFunc) error {
+	if err := c.RegisterTransform(name, function); err != nil {
+		return err
+	}
+	if c.preparers == nil {
+		c.preparers = make(map[string]PrepareFunc)
+	}
+	c.preparers[name] = prepare
+	return nil
+}
+
+// RegisterHashKey registers a secret key under the provided name for keyed hash
+// algorithms, such as `hmac-sha256` in the `hash` transform. Specs refer to the key by
+// name, so that the key itself is never part of a spec. Keys must be registered before
+// the Kazaam object using them is created with `New`.
+func (c *Config) RegisterHashKey(name string, key []byte) error {
+	if _, ok := c.hashKeys[name]; ok {
+		return errors.New("Hash key with that name already registered")
+	}
+	if c.hashKeys == nil {
+		c.hashKeys = make(map[string][]byte)
+	}
+	c.hashKeys[name] = append([]byte(nil), key...)
+	return nil
+}
+
+// RegisterLookupTable registers a table under the provided name for the `lookup`
+// transform, mapping values, in their string form, to any json-encodable values. This
+// allows large or shared tables, e.g. loaded from a file, to be kept out of specs.
+// Tables must be registered before the Kazaam object using them is created with `New`.
+func (c *Config) RegisterLookupTable(name string, table map[string]interface{}) error {
+	if _, ok := c.lookupTables[name]; ok {
+		return errors.New("Lookup table with that name already registered")
+	}
+	if c.lookupTables == nil {
+		c.lookupTables = make(map[string]map[string]interface{})
+	}
+	c.lookupTables[name] = table
+	return nil
+}
+
+// SetClock sets the function returning the current time for transforms, e.g. for
+// `$now` in the `timestamp` transform, instead of `time.Now`. A fixed clock makes the
+// output of such transforms reproducible, e.g. in tests. It must be set before the
+// Kazaam object using it is created with `New`.
+func (c *Config) SetClock(clock func() time.Time) {
+	c.clock = clock
+}
+
+// SetRand sets the source of randomness for transforms, e.g. of version 4 UUIDs in the
+// `uuid` transform, instead of `crypto/rand`. The reader must be safe for concurrent
+// use when the Kazaam object is. It must be set before the Kazaam object using it is
+// created with `New`.
+func (c *Config) SetRand(rand io.Reader) {
+	c.rand = rand
+}
+
 // Kazaam includes internal data required for handling the transformation.
 // A Kazaam object must be initialized using the `New` or `NewKazaam` functions.
 type Kazaam struct {
@@ -122,9 +262,10 @@ func NewKazaam(specString string) (*Kazaam, error) {
 //
 // At initialization time, the `spec` is checked to ensure that it is
 // valid JSON. Further, it confirms that all individual specs have a properly-specified
-// `operation` and details are set if required. If the spec is invalid, a nil Kazaam
-// pointer and an explanation of the error is returned. The contents of the transform
-// specification is further validated at Transform time.
+// `operation` and details are set if required. Transforms with a load-time step, such
+// as compiling regular expressions, are prepared here as well. If the spec is invalid,
+// a nil Kazaam pointer and an explanation of the error is returned. The contents of
+// the transform specification is further validated at Transform time.
 //
 // Currently, the Config object allows end users to register additional transform types
 // to support performing custom transformations not supported by the canonical set of
@@ -142,6 +283,17 @@ func New(specString string, config Config) (*Kazaam, error) {
 		if _, ok := config.transforms[*s.Operation]; !ok {
 			return nil, &Error{ErrMsg: "Invalid spec operation specified", ErrType: SpecError}
 		}
+		if s.Config != nil {
+			s.Config.HashKeys = config.hashKeys
+			s.Config.LookupTables = config.lookupTables
+			s.Config.Clock = config.clock
+			s.Config.Rand = config.rand
+		}
+		if prepare, ok := config.preparers[*s.Operation]; ok && s.Config != nil && s.Spec != nil {
+			if err := prepare(s.Config); err != nil {
+				return nil, &Error{ErrMsg: err.Error(), ErrType: SpecError}
+			}
+		}
 	}
 
 	j := Kazaam{spec: specString, specJSON: specElements, config: config}
@@ -209,6 +361,13 @@ func (k *Kazaam) TransformInPlace(data []byte) ([]byte, error) {
 				return data, transformErrorType(err)
 			}
 			for i, value := range transformedDataList {
+				apply, applyErr := specObj.applies(value)
+				if applyErr != nil {
+					return data, transformErrorType(applyErr)
+				}
+				if !apply {
+					continue
+				}
 				x := make([]byte, len(value))
 				copy(x, value)
 				x, intErr := k.getTransform(&specObj)(specObj.Config, x)
@@ -238,6 +397,13 @@ func (k *Kazaam) TransformInPlace(data []byte) ([]byte, error) {
 			}
 
 		} else {
+			apply, applyErr := specObj.applies(data)
+			if applyErr != nil {
+				return data, transformErrorType(applyErr)
+			}
+			if !apply {
+				continue
+			}
 			data, err = k.getTransform(&specObj)(specObj.Config, data)
 			if err != nil {
 				return data, transformErrorType(err)
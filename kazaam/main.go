@@ -3,12 +3,18 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/qntfy/kazaam/v4"
 )
@@ -19,9 +25,82 @@ var (
 	inFilename   = flag.String("in", "", "Input file (optional)")
 	outFilename  = flag.String("out", "", "Output file (optional)")
 	verbose      = flag.Bool("verbose", false, "Turn on verbose logging")
+	fixedTime    = flag.String("fixed-time", "", "Current time for transforms such as $now timestamps, in RFC 3339 format, for reproducible output (optional)")
+	lookups      lookupFlags
 )
 
-func loadKazaamTransform(specFilename string) (*kazaam.Kazaam, error) {
+func init() {
+	flag.Var(&lookups, "lookup", "Lookup table as name=file, from a .csv file of key,value rows or a .json object (optional, repeatable)")
+}
+
+// lookupFlags collects the name=file arguments of the repeatable -lookup flag.
+type lookupFlags []string
+
+func (l *lookupFlags) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *lookupFlags) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// newConfig returns the default Kazaam configuration with the lookup tables
+// registered, and a clock stopped at fixedTime when set.
+func newConfig(lookups []string, fixedTime string) (kazaam.Config, error) {
+	config := kazaam.NewDefaultConfig()
+	if fixedTime != "" {
+		t, err := time.Parse(time.RFC3339Nano, fixedTime)
+		if err != nil {
+			return config, errors.New("Fixed time must be in RFC 3339 format: " + err.Error())
+		}
+		config.SetClock(func() time.Time { return t })
+	}
+	for _, lookup := range lookups {
+		parts := strings.SplitN(lookup, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return config, errors.New("Lookup table must be given as name=file: " + lookup)
+		}
+		table, err := loadLookupTable(parts[1])
+		if err != nil {
+			return config, err
+		}
+		if err := config.RegisterLookupTable(parts[0], table); err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+// loadLookupTable reads a lookup table from a CSV file of key,value rows without a
+// header, or from a JSON file holding an object.
+func loadLookupTable(filename string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.New("Unable to read lookup table file: " + err.Error())
+	}
+	table := make(map[string]interface{})
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = 2
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, errors.New("Unable to parse lookup table file: " + err.Error())
+		}
+		for _, record := range records {
+			table[record[0]] = record[1]
+		}
+		return table, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&table); err != nil {
+		return nil, errors.New("Unable to parse lookup table file: " + err.Error())
+	}
+	return table, nil
+}
+
+func loadKazaamTransform(specFilename string, config kazaam.Config) (*kazaam.Kazaam, error) {
 	if specFilename == "" {
 		return nil, errors.New("Must specify a Kazaam specification file")
 	}
@@ -29,7 +108,7 @@ func loadKazaamTransform(specFilename string) (*kazaam.Kazaam, error) {
 	if specFileError != nil {
 		return nil, errors.New("Unable to read Kazaam specification file: " + specFileError.Error())
 	}
-	k, specError := kazaam.NewKazaam(string(specFile))
+	k, specError := kazaam.New(string(specFile), config)
 	if specError != nil {
 		return nil, errors.New("Unable to load Kazaam specification file: " + specError.Error())
 	}
@@ -56,7 +135,12 @@ func getInput(inputFilename string, file *os.File) (string, error) {
 func main() {
 	flag.Parse()
 
-	k, err := loadKazaamTransform(*specFilename)
+	config, err := newConfig(lookups, *fixedTime)
+	if err != nil {
+		log.Fatal("Trouble loading configuration", err)
+	}
+
+	k, err := loadKazaamTransform(*specFilename, config)
 	if err != nil {
 		log.Fatal("Trouble loading specification", err)
 	}
@@ -4,10 +4,12 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+
+	"github.com/qntfy/kazaam/v4"
 )
 
 func TestLoadKazaamTransformWithMissingFile(t *testing.T) {
-	_, err := loadKazaamTransform("")
+	_, err := loadKazaamTransform("", kazaam.NewDefaultConfig())
 
 	if err == nil {
 		t.Error("Should have errored for missing file")
@@ -15,7 +17,7 @@ func TestLoadKazaamTransformWithMissingFile(t *testing.T) {
 }
 
 func TestLoadKazaamTransformWithNoFile(t *testing.T) {
-	_, err := loadKazaamTransform("doesnt-exist")
+	_, err := loadKazaamTransform("doesnt-exist", kazaam.NewDefaultConfig())
 
 	if err == nil {
 		t.Error("Should have errored for non-existent file")
@@ -31,7 +33,7 @@ func TestLoadKazaamTransformWithInvalidFile(t *testing.T) {
 	defer os.Remove(fd.Name())
 	defer fd.Close()
 
-	_, err = loadKazaamTransform(fd.Name())
+	_, err = loadKazaamTransform(fd.Name(), kazaam.NewDefaultConfig())
 	if err == nil {
 		t.Error("Should have errored for empty file")
 	}
@@ -46,7 +48,7 @@ func TestLoadKazaamTransform(t *testing.T) {
 	fd.Close()
 	defer os.Remove(fd.Name())
 
-	_, err = loadKazaamTransform(fd.Name())
+	_, err = loadKazaamTransform(fd.Name(), kazaam.NewDefaultConfig())
 	if err != nil {
 		t.Error("Shouldn't have errored with valid transform", err)
 	}
@@ -123,3 +125,74 @@ func TestGetInputPriority(t *testing.T) {
 		t.Error("Unexpected file contents")
 	}
 }
+
+func TestNewConfigWithLookupTables(t *testing.T) {
+	csvFile, err := ioutil.TempFile("", "kz-main-test-*.csv")
+	if err != nil {
+		t.Fatal("Unable to create tmpfile for test", err)
+	}
+	csvFile.WriteString("US,USA\nDE,\"DEU\"\n")
+	csvFile.Close()
+	defer os.Remove(csvFile.Name())
+	jsonFile, err := ioutil.TempFile("", "kz-main-test-*.json")
+	if err != nil {
+		t.Fatal("Unable to create tmpfile for test", err)
+	}
+	jsonFile.WriteString(`{"M": "male", "F": "female"}`)
+	jsonFile.Close()
+	defer os.Remove(jsonFile.Name())
+
+	config, err := newConfig([]string{"iso3=" + csvFile.Name(), "gender=" + jsonFile.Name()}, "")
+	if err != nil {
+		t.Fatal("Shouldn't have errored with valid lookup tables", err)
+	}
+	k, err := kazaam.New(`[{"operation": "lookup", "spec": {"country": {"table": "iso3"}, "gender": {"table": "gender"}}}]`, config)
+	if err != nil {
+		t.Fatal("Shouldn't have errored with registered lookup tables", err)
+	}
+	out, err := k.TransformJSONStringToString(`{"country":"DE","gender":"F"}`)
+	if err != nil {
+		t.Fatal("Shouldn't have errored transforming", err)
+	}
+	if out != `{"country":"DEU","gender":"female"}` {
+		t.Error("Unexpected output", out)
+	}
+}
+
+func TestNewConfigWithInvalidLookupTables(t *testing.T) {
+	csvFile, err := ioutil.TempFile("", "kz-main-test-*.csv")
+	if err != nil {
+		t.Fatal("Unable to create tmpfile for test", err)
+	}
+	csvFile.WriteString("US,USA,extra\n")
+	csvFile.Close()
+	defer os.Remove(csvFile.Name())
+
+	for _, lookup := range []string{"no-file", "=file", "name=doesnt-exist", "name=" + csvFile.Name()} {
+		if _, err := newConfig([]string{lookup}, ""); err == nil {
+			t.Error("Should have errored for invalid lookup table", lookup)
+		}
+	}
+}
+
+func TestNewConfigWithFixedTime(t *testing.T) {
+	config, err := newConfig(nil, "2020-01-15T10:00:00+02:00")
+	if err != nil {
+		t.Fatal("Shouldn't have errored with a valid fixed time", err)
+	}
+	k, err := kazaam.New(`[{"operation": "timestamp", "spec": {"at": {"inputFormat": "$now", "outputFormat": "2006-01-02T15:04:05Z07:00"}}}]`, config)
+	if err != nil {
+		t.Fatal("Shouldn't have errored loading the spec", err)
+	}
+	out, err := k.TransformJSONStringToString(`{}`)
+	if err != nil {
+		t.Fatal("Shouldn't have errored transforming", err)
+	}
+	if out != `{"at":"2020-01-15T10:00:00+02:00"}` {
+		t.Error("Unexpected output", out)
+	}
+
+	if _, err := newConfig(nil, "2020-01-15 10:00"); err == nil {
+		t.Error("Should have errored for an invalid fixed time")
+	}
+}
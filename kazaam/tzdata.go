@@ -0,0 +1,7 @@
+//go:build go1.15
+// +build go1.15
+
+package main
+
+// embed the time zone database so that timestamp time zones work on any host
+import _ "time/tzdata"
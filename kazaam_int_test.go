@@ -524,3 +524,85 @@ func TestKazaamOverRootArray(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestKazaamWhen(t *testing.T) {
+	spec := `[{
+		"operation": "default",
+		"when": "status == 'active' && len(items) > 0",
+		"spec": {"billable": true}
+	}, {
+		"operation": "default",
+		"when": "missing",
+		"spec": {"skipped": true}
+	}]`
+	testCases := []struct {
+		jsonIn  string
+		jsonOut string
+	}{
+		{`{"status":"active","items":[1]}`, `{"status":"active","items":[1],"billable":true}`},
+		{`{"status":"active","items":[]}`, `{"status":"active","items":[]}`},
+		{`{"status":"closed","items":[1]}`, `{"status":"closed","items":[1]}`},
+	}
+
+	kazaamTransform, err := kazaam.NewKazaam(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tc := range testCases {
+		kazaamOut, _ := kazaamTransform.TransformJSONStringToString(tc.jsonIn)
+		areEqual, _ := checkJSONStringsEqual(kazaamOut, tc.jsonOut)
+		if !areEqual {
+			t.Error("Transformed data does not match expectation.")
+			t.Log("Expected: ", tc.jsonOut)
+			t.Log("Actual:   ", kazaamOut)
+		}
+	}
+}
+
+func TestKazaamWhenWithOver(t *testing.T) {
+	spec := `[{
+		"operation": "expr",
+		"over": "items",
+		"when": "qty > 1",
+		"spec": {"bulk": "upper(sku)"}
+	}]`
+	jsonIn := `{"items":[{"sku":"a","qty":1},{"sku":"b","qty":5}]}`
+	jsonOut := `{"items":[{"sku":"a","qty":1},{"sku":"b","qty":5,"bulk":"B"}]}`
+
+	kazaamTransform, _ := kazaam.NewKazaam(spec)
+	kazaamOut, _ := kazaamTransform.TransformJSONStringToString(jsonIn)
+	areEqual, _ := checkJSONStringsEqual(kazaamOut, jsonOut)
+
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected: ", jsonOut)
+		t.Log("Actual:   ", kazaamOut)
+		t.FailNow()
+	}
+}
+
+func TestKazaamWhenInvalid(t *testing.T) {
+	_, err := kazaam.NewKazaam(`[{"operation": "pass", "when": "a =="}]`)
+	if err == nil {
+		t.Error("Should have thrown error for an invalid when condition")
+	}
+	if e, ok := err.(*kazaam.Error); !ok || e.ErrType != kazaam.SpecError {
+		t.Errorf("got %v; want a SpecError", err)
+	}
+
+	kazaamTransform, _ := kazaam.NewKazaam(`[{"operation": "pass", "when": "a < 'b'"}]`)
+	_, err = kazaamTransform.TransformJSONStringToString(`{"a":1}`)
+	if err == nil {
+		t.Error("Should have thrown error comparing a number to a string")
+	}
+
+	// a condition that fails to evaluate aborts the transform, rather than skipping the spec
+	kazaamTransform, _ = kazaam.NewKazaam(`[{"operation": "default", "when": "b > 1", "spec": {"c": true}}]`)
+	kazaamOut, err := kazaamTransform.TransformJSONStringToString(`{"b":"x"}`)
+	if e, ok := err.(*kazaam.Error); !ok || e.ErrType != kazaam.ParseError {
+		t.Errorf("got %v; want a ParseError", err)
+	}
+	if kazaamOut != "" {
+		t.Errorf("got %s; want no output", kazaamOut)
+	}
+}
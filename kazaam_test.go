@@ -1,8 +1,11 @@
 package kazaam
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/qntfy/jsonparser"
 	"github.com/qntfy/kazaam/v4/transform"
@@ -37,8 +40,104 @@ func TestReregisterKazaamTransform(t *testing.T) {
 	}
 }
 
+func TestKazaamWithRegisteredPrepare(t *testing.T) {
+	kc := NewDefaultConfig()
+	err := kc.RegisterTransformWithPrepare("3rd-party", func(spec *transform.Config, data []byte) ([]byte, error) {
+		return data, nil
+	}, func(spec *transform.Config) error {
+		if _, ok := (*spec.Spec)["required"]; !ok {
+			return errors.New("missing required key")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := New(`[{"operation": "3rd-party", "spec": {"required": true}}]`, kc); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err = New(`[{"operation": "3rd-party", "spec": {"other": true}}]`, kc)
+	if e, ok := err.(*Error); !ok || e.ErrType != SpecError {
+		t.Errorf("got %v; want a SpecError", err)
+	}
+
+	if err := kc.RegisterTransformWithPrepare("shift", nil, nil); err == nil {
+		t.Error("Should have thrown error for duplicated transform name")
+	}
+}
+
+func TestKazaamWithRegisteredHashKey(t *testing.T) {
+	kc := NewDefaultConfig()
+	if err := kc.RegisterHashKey("pii", []byte("secret")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	k, err := New(`[{"operation": "hash", "spec": {"email": {"algorithm": "hmac-sha256", "key": "pii"}}}]`, kc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := k.TransformJSONStringToString(`{"email":"a@example.com"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"email":"0607236cc2fc521ca815254262b7014cb54eb5488f266e4777158cc52a33cfe9"}`
+	if out != expected {
+		t.Errorf("got %s; want %s", out, expected)
+	}
+
+	if err := kc.RegisterHashKey("pii", []byte("other")); err == nil {
+		t.Error("Should have thrown error for duplicated hash key name")
+	}
+	if _, err := NewKazaam(`[{"operation": "hash", "spec": {"email": {"algorithm": "hmac-sha256", "key": "pii"}}}]`); err == nil {
+		t.Error("Should have thrown error for an unregistered hash key")
+	}
+}
+
+func TestKazaamWithRegisteredLookupTable(t *testing.T) {
+	kc := NewDefaultConfig()
+	if err := kc.RegisterLookupTable("iso3", map[string]interface{}{"US": "USA", "DE": "DEU"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	k, err := New(`[{"operation": "lookup", "spec": {"country": {"table": "iso3", "default": null}}}]`, kc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := k.TransformJSONStringToString(`{"country":"DE"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"country":"DEU"}`
+	if out != expected {
+		t.Errorf("got %s; want %s", out, expected)
+	}
+
+	if err := kc.RegisterLookupTable("iso3", nil); err == nil {
+		t.Error("Should have thrown error for duplicated lookup table name")
+	}
+	if _, err := NewKazaam(`[{"operation": "lookup", "spec": {"country": {"table": "iso3"}}}]`); err == nil {
+		t.Error("Should have thrown error for an unregistered lookup table")
+	}
+}
+
+func TestKazaamWithClockAndRand(t *testing.T) {
+	kc := NewDefaultConfig()
+	kc.SetClock(func() time.Time { return time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC) })
+	kc.SetRand(bytes.NewReader(make([]byte, 16)))
+	k, err := New(`[{"operation": "timestamp", "spec": {"at": {"inputFormat": "$now", "outputFormat": "$unix"}}}, {"operation": "uuid", "spec": {"id": {"version": 4}}}]`, kc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := k.TransformJSONStringToString(`{}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"at":"1579082400","id":"00000000-0000-4000-8000-000000000000"}`
+	if out != expected {
+		t.Errorf("got %s; want %s", out, expected)
+	}
+}
+
 func TestDefaultTransformsSetCardinarily(t *testing.T) {
-	if len(validSpecTypes) != 9 {
+	if len(validSpecTypes) != 35 {
 		t.Error("Unexpected number of default transforms. Missing tests?")
 	}
 }
@@ -109,3 +208,97 @@ func ExampleConfig_RegisterTransform() {
 	// Output:
 	// {"input":72,"output":72}
 }
+
+func TestNewPreparesTransforms(t *testing.T) {
+	_, err := NewKazaam(`[{"operation": "regex", "spec": {"note": {"pattern": "(", "replacement": ""}}}]`)
+	if err == nil {
+		t.Fatal("Should have thrown error for an invalid regex at load time")
+	}
+	if e, ok := err.(*Error); !ok || e.ErrType != SpecError {
+		t.Errorf("got %v; want a SpecError", err)
+	}
+
+	k, err := NewKazaam(`[{"operation": "regex", "spec": {"note": {"pattern": "\\d", "replacement": "#"}}}]`)
+	if err != nil {
+		t.Fatalf("Shouldn't have thrown error for a valid regex: %v", err)
+	}
+	out, _ := k.TransformJSONStringToString(`{"note":"a1b2"}`)
+	if out != `{"note":"a#b#"}` {
+		t.Errorf("got %s; want %s", out, `{"note":"a#b#"}`)
+	}
+}
+
+func TestNewRejectsInvalidSpecs(t *testing.T) {
+	testCases := []string{
+		`[{"operation": "cast", "spec": {"age": "integer128"}}]`,
+		`[{"operation": "case", "spec": {"name": "sponge"}}]`,
+	}
+
+	for _, spec := range testCases {
+		_, err := NewKazaam(spec)
+		if e, ok := err.(*Error); !ok || e.ErrType != SpecError {
+			t.Errorf("got %v; want a SpecError for spec %s", err, spec)
+		}
+	}
+}
+
+func TestNewPreparesComputedDefaults(t *testing.T) {
+	_, err := NewKazaam(`[{"operation": "default", "spec": {"name": {"$expr": "first +"}}}]`)
+	if err == nil {
+		t.Fatal("Should have thrown error for an invalid expression at load time")
+	}
+
+	k, err := NewKazaam(`[{"operation": "default", "spec": {"name": {"$expr": "first + ' ' + last"}, "kind": "person"}}]`)
+	if err != nil {
+		t.Fatalf("Shouldn't have thrown error for a valid expression: %v", err)
+	}
+	out, _ := k.TransformJSONStringToString(`{"first":"Ada","last":"Lovelace"}`)
+	if out != `{"first":"Ada","last":"Lovelace","kind":"person","name":"Ada Lovelace"}` {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestKazaamFlattenWithoutSpec(t *testing.T) {
+	testCases := []struct {
+		spec string
+		in   string
+		want string
+	}{
+		{`[{"operation": "flatten", "spec": {}}]`, `{"user":{"name":"Ada","phones":["555-1234"]}}`, `{"user.name":"Ada","user.phones.0":"555-1234"}`},
+		{`[{"operation": "flatten"}]`, `{"user":{"name":"Ada","phones":["555-1234"]}}`, `{"user.name":"Ada","user.phones.0":"555-1234"}`},
+		{`[{"operation": "flatten", "require": true}]`, `{"user":{"name":"Ada"}}`, `{"user.name":"Ada"}`},
+		{`[{"operation": "unflatten", "spec": {}}]`, `{"user.name":"Ada","user.phones.0":"555-1234"}`, `{"user":{"name":"Ada","phones":["555-1234"]}}`},
+		{`[{"operation": "unflatten"}]`, `{"user.name":"Ada","user.phones.0":"555-1234"}`, `{"user":{"name":"Ada","phones":["555-1234"]}}`},
+	}
+
+	for _, tc := range testCases {
+		k, err := NewKazaam(tc.spec)
+		if err != nil {
+			t.Fatalf("Shouldn't have thrown error for spec %s: %v", tc.spec, err)
+		}
+		out, err := k.TransformJSONStringToString(tc.in)
+		if err != nil {
+			t.Fatalf("unexpected error for spec %s: %v", tc.spec, err)
+		}
+		if out != tc.want {
+			t.Errorf("got %s; want %s", out, tc.want)
+		}
+	}
+}
+
+func TestNewPreparesFlattenWithoutSpec(t *testing.T) {
+	for _, spec := range []string{`[{"operation": "flatten"}]`, `[{"operation": "flatten", "require": true}]`} {
+		kc := NewDefaultConfig()
+		prepared := 0
+		kc.preparers["flatten"] = func(spec *transform.Config) error {
+			prepared++
+			return transform.PrepareFlatten(spec)
+		}
+		if _, err := New(spec, kc); err != nil {
+			t.Fatalf("Shouldn't have thrown error for spec %s: %v", spec, err)
+		}
+		if prepared != 1 {
+			t.Errorf("got %d preparations; want 1 for spec %s", prepared, spec)
+		}
+	}
+}
@@ -2,22 +2,33 @@ package kazaam
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/qntfy/kazaam/v4/transform"
 )
 
 // Spec represents an individual spec element. It describes the name of the operation,
-// whether the `over` operator is required, and an operation-specific `Config` that
-// describes the configuration of the transform.
+// whether the `over` operator is required, an optional `when` condition, and an
+// operation-specific `Config` that describes the configuration of the transform.
 type spec struct {
 	*transform.Config
 	Operation *string `json:"operation"`
 	Over      *string `json:"over,omitempty"`
+	When      *string `json:"when,omitempty"`
+	// when is the parsed When expression
+	when *transform.Expression
 }
 
 type specInt spec
 type specs []spec
 
+// emptySpecOperations are the operations whose spec may be empty or missing, to apply
+// the transform with its default options.
+var emptySpecOperations = map[string]bool{
+	"flatten":   true,
+	"unflatten": true,
+}
+
 // UnmarshalJSON implements a custon unmarshaller for the Spec type
 func (s *spec) UnmarshalJSON(b []byte) (err error) {
 	j := specInt{}
@@ -27,14 +38,44 @@ func (s *spec) UnmarshalJSON(b []byte) (err error) {
 			err = &Error{ErrMsg: "Spec must contain an \"operation\" field", ErrType: SpecError}
 			return
 		}
-		if s.Config != nil && s.Spec != nil && len(*s.Spec) < 1 {
+		if s.Config != nil && s.Spec != nil && len(*s.Spec) < 1 && !emptySpecOperations[*s.Operation] {
 			err = &Error{ErrMsg: "Spec must contain at least one element", ErrType: SpecError}
 			return
 		}
+		// a missing spec is an empty one, so that it is prepared and validated by New
+		// like any other
+		if emptySpecOperations[*s.Operation] {
+			if s.Config == nil {
+				s.Config = &transform.Config{}
+			}
+			if s.Spec == nil {
+				s.Spec = &map[string]interface{}{}
+			}
+		}
 		if s.Config != nil && s.KeySeparator == "" {
 			s.KeySeparator = "."
 		}
+		if s.When != nil {
+			if s.when, err = transform.ParseExpression(*s.When); err != nil {
+				err = &Error{ErrMsg: fmt.Sprintf("Invalid \"when\" condition: %v", err), ErrType: SpecError}
+				return
+			}
+		}
 		return
 	}
 	return
 }
+
+// applies reports whether the spec should be applied to data, which is the case
+// unless its `when` condition evaluates to a falsy value. A condition that fails to
+// evaluate is an error, which aborts the transform, rather than false.
+func (s *spec) applies(data []byte) (bool, error) {
+	if s.when == nil {
+		return true, nil
+	}
+	keySeparator := "."
+	if s.Config != nil {
+		keySeparator = s.KeySeparator
+	}
+	return s.when.EvalBool(data, keySeparator)
+}
@@ -0,0 +1,230 @@
+package transform
+
+import (
+	"fmt"
+	"time"
+)
+
+// aggregateSpec describes the aggregate computed for a single target path.
+type aggregateSpec struct {
+	fn   string
+	path string
+	// key converts and compares the values for min and max
+	key sortKey
+}
+
+// aggregateFuncs computes an aggregate over the raw, non-null values at the path.
+var aggregateFuncs = map[string]func(a *aggregateSpec, values [][]byte) (interface{}, error){
+	"count":         aggregateCount,
+	"sum":           aggregateSum,
+	"avg":           aggregateAvg,
+	"min":           func(a *aggregateSpec, values [][]byte) (interface{}, error) { return a.extreme(values, -1) },
+	"max":           func(a *aggregateSpec, values [][]byte) (interface{}, error) { return a.extreme(values, 1) },
+	"first":         aggregateFirst,
+	"last":          aggregateLast,
+	"distinctCount": aggregateDistinctCount,
+}
+
+// Aggregate sets each target path to an aggregate, such as a sum or a count, of the
+// values at a source path. A source path with `[*]` wildcards, or holding an array,
+// provides multiple values.
+func Aggregate(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseAggregateSpecs)
+	if err != nil {
+		return nil, err
+	}
+	// every aggregate is computed from the input data, before any result is set
+	var results []exprResult
+	for k, a := range parsed.(map[string]*aggregateSpec) {
+		targets, err := expandWildcards(data, k, false, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			// wildcards shared with the target refer to the same elements
+			values, err := aggregateValues(spec, data, fillWildcards(a.path, target.indexes))
+			if err != nil {
+				return nil, err
+			}
+			result, err := aggregateFuncs[a.fn](a, values)
+			if err != nil {
+				return nil, err
+			}
+			value, err := encodeExprValue(result)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, exprResult{path: target.path, value: value})
+		}
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareAggregate parses the aggregate spec ahead of transforming any data.
+func PrepareAggregate(spec *Config) error {
+	return spec.prepareWith(parseAggregateSpecs)
+}
+
+func parseAggregateSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*aggregateSpec)
+	for k, v := range *spec.Spec {
+		aggregateMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		a := &aggregateSpec{key: sortKey{path: "$", kind: sortTypeAuto, format: time.RFC3339}}
+		if a.fn, ok = aggregateMap["fn"].(string); !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"fn\" for key: %s", k))
+		}
+		if _, ok = aggregateFuncs[a.fn]; !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown fn %q for key: %s", a.fn, k))
+		}
+		if a.path, ok = aggregateMap["path"].(string); !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"path\" for key: %s", k))
+		}
+		// type and format are those of the sort transform
+		var err error
+		if a.key, err = newSortKey(aggregateMap, a.key); err != nil {
+			return nil, SpecError(fmt.Sprintf("%v for key: %s", err, k))
+		}
+		specs[k] = a
+	}
+	return specs, nil
+}
+
+// aggregateValues returns the raw non-null values at path, expanding its wildcards
+// and the elements of an array found at a path without wildcards.
+func aggregateValues(spec *Config, data []byte, path string) ([][]byte, error) {
+	paths, err := expandWildcards(data, path, spec.Require, spec.KeySeparator)
+	if err != nil {
+		return nil, err
+	}
+	var values [][]byte
+	for _, p := range paths {
+		dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		elements := [][]byte{dataForV}
+		if dataForV[0] == '[' && p.path == path {
+			if elements, err = arrayElements(dataForV); err != nil {
+				return nil, err
+			}
+		}
+		for _, element := range elements {
+			if string(element) != "null" {
+				values = append(values, element)
+			}
+		}
+	}
+	return values, nil
+}
+
+func aggregateCount(a *aggregateSpec, values [][]byte) (interface{}, error) {
+	return int64(len(values)), nil
+}
+
+// aggregateNumbers decodes the values as numbers. Strings holding a number are
+// converted; any other value is an error.
+func aggregateNumbers(a *aggregateSpec, values [][]byte) ([]interface{}, error) {
+	numbers := make([]interface{}, len(values))
+	for i, value := range values {
+		decoded, err := decodeJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		decoded = normalizeNumbers(decoded)
+		if _, ok := decoded.(string); ok {
+			decoded, _ = exprToNumber([]interface{}{decoded})
+		}
+		if !isNumber(decoded) {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to %s non-numeric value at %s: %s", a.fn, a.path, value))
+		}
+		numbers[i] = decoded
+	}
+	return numbers, nil
+}
+
+func aggregateSum(a *aggregateSpec, values [][]byte) (interface{}, error) {
+	numbers, err := aggregateNumbers(a, values)
+	if err != nil {
+		return nil, err
+	}
+	return exprSum(numbers)
+}
+
+func aggregateAvg(a *aggregateSpec, values [][]byte) (interface{}, error) {
+	numbers, err := aggregateNumbers(a, values)
+	if err != nil {
+		return nil, err
+	}
+	return exprAvg(numbers)
+}
+
+// extreme returns the original value that is smallest (sign < 0) or largest
+// (sign > 0) once converted to the type of the key. Values that cannot be converted
+// are ignored.
+func (a *aggregateSpec) extreme(values [][]byte, sign int) (interface{}, error) {
+	var best []byte
+	var bestValue interface{}
+	for _, value := range values {
+		converted, err := a.key.value(value, ".")
+		if err != nil {
+			return nil, err
+		}
+		if converted == nil {
+			continue
+		}
+		if best == nil || compareSortValues(converted, bestValue) == sign {
+			best, bestValue = value, converted
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return decodeAggregateValue(best)
+}
+
+func aggregateFirst(a *aggregateSpec, values [][]byte) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return decodeAggregateValue(values[0])
+}
+
+func aggregateLast(a *aggregateSpec, values [][]byte) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return decodeAggregateValue(values[len(values)-1])
+}
+
+// aggregateDistinctCount counts the distinct values, comparing them as the unique
+// transform does.
+func aggregateDistinctCount(a *aggregateSpec, values [][]byte) (interface{}, error) {
+	u := &uniqueSpec{by: "$"}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		key, err := u.key(value, ".")
+		if err != nil {
+			return nil, err
+		}
+		seen[key] = true
+	}
+	return int64(len(seen)), nil
+}
+
+// decodeAggregateValue decodes a raw value for re-encoding, keeping integers exact.
+func decodeAggregateValue(value []byte) (interface{}, error) {
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(decoded), nil
+}
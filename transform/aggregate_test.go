@@ -0,0 +1,105 @@
+package transform
+
+import "testing"
+
+func TestAggregate(t *testing.T) {
+	jsonIn := `{"id":9007199254740993,"items":[{"sku":"a","price":2.5,"qty":2},{"sku":"b","price":"10","qty":1},{"sku":"a","price":null,"qty":3}],"events":[{"at":"2020-01-02T00:00:00+05:00"},{"at":"2020-01-01T20:00:00Z"},{"at":"bad"}],"tags":["x","y","x"]}`
+	testCases := []struct {
+		name   string
+		spec   string
+		target string
+		want   string
+	}{
+		{"count skips nulls", `{"n": {"fn": "count", "path": "items[*].price"}}`, "n", `2`},
+		{"count of array", `{"n": {"fn": "count", "path": "items"}}`, "n", `3`},
+		{"count of missing", `{"n": {"fn": "count", "path": "missing[*].x"}}`, "n", `0`},
+		{"sum of integers", `{"n": {"fn": "sum", "path": "items[*].qty"}}`, "n", `6`},
+		{"sum converts numeric strings", `{"n": {"fn": "sum", "path": "items[*].price"}}`, "n", `12.5`},
+		{"sum of nothing", `{"n": {"fn": "sum", "path": "missing"}}`, "n", `0`},
+		{"avg", `{"n": {"fn": "avg", "path": "items[*].qty"}}`, "n", `2`},
+		{"avg of nothing", `{"n": {"fn": "avg", "path": "missing"}}`, "n", `null`},
+		{"min of numbers", `{"n": {"fn": "min", "path": "items[*].qty"}}`, "n", `1`},
+		{"max of strings", `{"n": {"fn": "max", "path": "items[*].sku"}}`, "n", `"b"`},
+		{"max of numbers as numbers", `{"n": {"fn": "max", "path": "items[*].price", "type": "number"}}`, "n", `"10"`},
+		{"latest time keeps the original value", `{"n": {"fn": "max", "path": "events[*].at", "type": "time"}}`, "n", `"2020-01-01T20:00:00Z"`},
+		{"earliest time", `{"n": {"fn": "min", "path": "events[*].at", "type": "time"}}`, "n", `"2020-01-02T00:00:00+05:00"`},
+		{"first", `{"n": {"fn": "first", "path": "items[*].sku"}}`, "n", `"a"`},
+		{"last skips nulls", `{"n": {"fn": "last", "path": "items[*].price"}}`, "n", `"10"`},
+		{"last of missing", `{"n": {"fn": "last", "path": "missing"}}`, "n", `null`},
+		{"distinct count", `{"n": {"fn": "distinctCount", "path": "tags"}}`, "n", `2`},
+		{"integers stay exact", `{"n": {"fn": "max", "path": "id"}}`, "n", `9007199254740993`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Aggregate, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := getJSONRaw(kazaamOut, tc.target, true, ".")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAggregatePerElement(t *testing.T) {
+	spec := `{"orders[*].total": {"fn": "sum", "path": "orders[*].items[*].price"}, "count": {"fn": "count", "path": "orders[*].items[*]"}}`
+	jsonIn := `{"orders":[{"items":[{"price":1},{"price":2}]},{"items":[{"price":5}]}]}`
+	jsonOut := `{"orders":[{"items":[{"price":1},{"price":2}],"total":3},{"items":[{"price":5}],"total":5}],"count":3}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Aggregate, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		require bool
+	}{
+		{`{"n": {"fn": "sum", "path": "names"}}`, false},
+		{`{"n": {"fn": "avg", "path": "flags"}}`, false},
+		{`{"n": {"fn": "count", "path": "missing"}}`, true},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, tc.require)
+		_, err := getTransformTestWrapper(Aggregate, cfg, `{"names":["a"],"flags":[true]}`)
+		if err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Spec:       ", tc.spec)
+		}
+	}
+}
+
+func TestAggregateInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"n": "sum"}`,
+		`{"n": {"path": "a"}}`,
+		`{"n": {"fn": "median", "path": "a"}}`,
+		`{"n": {"fn": "sum"}}`,
+		`{"n": {"fn": "max", "path": "a", "type": "date"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareAggregate(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid aggregate spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// supported casing styles
+var caseStyles = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": titleCase,
+	"camel": camelCase,
+	"snake": func(s string) string { return joinWords(s, "_") },
+	"kebab": func(s string) string { return joinWords(s, "-") },
+}
+
+// Case changes the casing of the string values at the provided paths. Arrays of
+// strings have every string element converted.
+func Case(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseCaseSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for k, convert := range parsed.(map[string]func(string) string) {
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			// only strings and arrays of strings are converted, other values are left as-is
+			if dataForV[0] != '"' && dataForV[0] != '[' {
+				continue
+			}
+			decoded, err := decodeJSON(dataForV)
+			if err != nil {
+				return nil, err
+			}
+			switch decodedTyped := decoded.(type) {
+			case string:
+				decoded = convert(decodedTyped)
+			case []interface{}:
+				for i, item := range decodedTyped {
+					if itemStr, ok := item.(string); ok {
+						decodedTyped[i] = convert(itemStr)
+					}
+				}
+			}
+			out, err := encodeJSON(decoded)
+			if err != nil {
+				return nil, err
+			}
+			// skip the write when nothing changed
+			if bytes.Equal(out, dataForV) {
+				continue
+			}
+			data, err = setJSONRaw(data, out, p.path, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// PrepareCase parses the case spec ahead of transforming any data.
+func PrepareCase(spec *Config) error {
+	return spec.prepareWith(parseCaseSpecs)
+}
+
+func parseCaseSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]func(string) string)
+	for k, v := range *spec.Spec {
+		style, ok := v.(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		if specs[k], ok = caseStyles[style]; !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown case %q for key: %s", style, k))
+		}
+	}
+	return specs, nil
+}
+
+// titleCase upper-cases the first letter of every word and lower-cases the rest.
+// Apostrophes do not start a new word, so "o'neil" becomes "O'neil".
+func titleCase(s string) string {
+	var buffer strings.Builder
+	previous := ' '
+	for _, r := range s {
+		if isWordRune(previous) || previous == '\'' {
+			buffer.WriteRune(unicode.ToLower(r))
+		} else {
+			buffer.WriteRune(unicode.ToTitle(r))
+		}
+		previous = r
+	}
+	return buffer.String()
+}
+
+// camelCase joins the words of s with the first word lower-cased and the first
+// letter of every following word upper-cased.
+func camelCase(s string) string {
+	var buffer strings.Builder
+	for i, word := range splitWords(s) {
+		if i == 0 {
+			buffer.WriteString(strings.ToLower(word))
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		buffer.WriteRune(unicode.ToTitle(first))
+		buffer.WriteString(strings.ToLower(word[size:]))
+	}
+	return buffer.String()
+}
+
+// joinWords lower-cases the words of s and joins them with sep.
+func joinWords(s, sep string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, sep)
+}
+
+// splitWords breaks s into words on any character that is not a letter or digit,
+// and on changes of case: "userID", "user_id" and "User-Id" all become ["user",
+// "ID"/"id"/"Id"], while an acronym followed by a word, as in "HTTPServer", becomes
+// ["HTTP", "Server"].
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !isWordRune(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(previous) || nextIsLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
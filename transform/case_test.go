@@ -0,0 +1,99 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCase(t *testing.T) {
+	spec := `{"country": "upper", "email": "lower", "name": "title", "tags": "kebab", "items[*].code": "snake", "field": "camel", "count": "upper"}`
+	jsonIn := `{"country":"us","email":"Jane.Doe@Example.COM","name":"jOHN o'neil-smith","tags":["New Arrival","onSale",3],"items":[{"code":"HTTPServer"},{"code":"userID"}],"field":"first_name","count":3}`
+	jsonOut := `{"country":"US","email":"jane.doe@example.com","name":"John O'neil-Smith","tags":["new-arrival","on-sale",3],"items":[{"code":"http_server"},{"code":"user_id"}],"field":"firstName","count":3}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Case, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error in transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestCaseUnicodeAndEscapes(t *testing.T) {
+	spec := `{"city": "upper", "name": "title", "quote": "lower"}`
+	jsonIn := `{"city":"münchen ölçek","name":"émile zola","quote":"\"SAY\" ÉTÉ"}`
+	jsonOut := `{"city":"MÜNCHEN ÖLÇEK","name":"Émile Zola","quote":"\"say\" été"}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, _ := getTransformTestWrapper(Case, cfg, jsonIn)
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestCaseRequire(t *testing.T) {
+	cfg := getConfig(`{"missing": "upper"}`, true)
+	_, err := getTransformTestWrapper(Case, cfg, testJSONInput)
+
+	if err == nil {
+		t.Error("Transform path does not exist in message and should throw an error")
+		t.FailNow()
+	}
+}
+
+func TestCaseInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"name": "sponge"}`,
+		`{"name": {"case": "upper"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		_, err := getTransformTestWrapper(Case, cfg, testJSONInput)
+		if _, ok := err.(SpecError); !ok {
+			t.Error("Should have thrown a SpecError for an invalid case spec.")
+			t.Log("Spec:       ", spec)
+			t.Log("Error:      ", err)
+		}
+		cfg = getConfig(spec, false)
+		if err := PrepareCase(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError preparing an invalid case spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"user_id", []string{"user", "id"}},
+		{"userID", []string{"user", "ID"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"  first--Name  ", []string{"first", "Name"}},
+		{"v2Api", []string{"v2", "Api"}},
+		{"ÉcoleNormale", []string{"École", "Normale"}},
+	}
+
+	for _, tc := range testCases {
+		if words := splitWords(tc.input); !reflect.DeepEqual(words, tc.expected) {
+			t.Errorf("got %q; want %q", words, tc.expected)
+		}
+	}
+}
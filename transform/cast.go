@@ -0,0 +1,275 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// castSpec describes the conversion applied to the values at a single path.
+type castSpec struct {
+	typ        string
+	trueValues []string
+	// falseValues is only consulted when trueValues is set
+	falseValues []string
+}
+
+// Cast converts the values at the provided paths to the requested json type. Values
+// that cannot be converted are left unchanged, or fail the transform when `require`
+// is set.
+func Cast(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseCastSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for k, c := range parsed.(map[string]*castSpec) {
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			// nothing to convert, bail and keep iterating
+			if bytes.Equal(dataForV, []byte("null")) {
+				continue
+			}
+			converted, err := c.convert(dataForV)
+			if err != nil {
+				if spec.Require {
+					return nil, err
+				}
+				continue
+			}
+			data, err = setJSONRaw(data, converted, p.path, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// PrepareCast parses the cast spec ahead of transforming any data.
+func PrepareCast(spec *Config) error {
+	return spec.prepareWith(parseCastSpecs)
+}
+
+func parseCastSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*castSpec)
+	for k, v := range *spec.Spec {
+		c, err := newCastSpec(k, v)
+		if err != nil {
+			return nil, err
+		}
+		specs[k] = c
+	}
+	return specs, nil
+}
+
+// newCastSpec builds a castSpec from either a type name or an object with a `type`
+// field and, for booleans, optional `true` and `false` value lists.
+func newCastSpec(key string, v interface{}) (*castSpec, error) {
+	c := &castSpec{}
+	switch vTyped := v.(type) {
+	case string:
+		c.typ = vTyped
+	case map[string]interface{}:
+		typ, ok := vTyped["type"].(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"type\" for key: %s", key))
+		}
+		c.typ = typ
+		var err error
+		if c.trueValues, err = castValueList(key, vTyped, "true"); err != nil {
+			return nil, err
+		}
+		if c.falseValues, err = castValueList(key, vTyped, "false"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", key))
+	}
+	switch c.typ {
+	case "int", "float", "string", "bool":
+	default:
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown type %q for key: %s", c.typ, key))
+	}
+	return c, nil
+}
+
+// castValueList reads a list of literal values from the spec as strings.
+func castValueList(key string, spec map[string]interface{}, field string) ([]string, error) {
+	listInterface, ok := spec[field]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := listInterface.([]interface{})
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a list for key: %s", field, key))
+	}
+	var values []string
+	for _, item := range list {
+		switch itemTyped := item.(type) {
+		case string:
+			values = append(values, itemTyped)
+		default:
+			values = append(values, fmt.Sprint(itemTyped))
+		}
+	}
+	return values, nil
+}
+
+// convert returns the raw json representation of value as the spec's type.
+func (c *castSpec) convert(value []byte) ([]byte, error) {
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	var converted []byte
+	var ok bool
+	switch c.typ {
+	case "int":
+		converted, ok = castToInt(decoded)
+	case "float":
+		converted, ok = castToFloat(decoded)
+	case "string":
+		converted, ok = castToString(decoded, value)
+	case "bool":
+		converted, ok = c.castToBool(decoded)
+	}
+	if !ok {
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to cast %s to %s", value, c.typ))
+	}
+	return converted, nil
+}
+
+// castToInt converts numbers, numeric strings and booleans to integers. Numbers with a
+// fractional part, such as `3.7`, are not integers and cannot be converted, rather than
+// being truncated.
+func castToInt(value interface{}) ([]byte, bool) {
+	var text string
+	switch valueTyped := value.(type) {
+	case json.Number:
+		text = valueTyped.String()
+	case string:
+		text = strings.TrimSpace(valueTyped)
+	case bool:
+		if valueTyped {
+			return []byte("1"), true
+		}
+		return []byte("0"), true
+	default:
+		return nil, false
+	}
+	// parse integers directly so that large values do not lose precision
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return []byte(strconv.FormatInt(i, 10)), true
+	}
+	// integral values in other notations, such as `2.0` or `1e3`
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || f != math.Trunc(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return nil, false
+	}
+	return []byte(strconv.FormatInt(int64(f), 10)), true
+}
+
+func castToFloat(value interface{}) ([]byte, bool) {
+	var f float64
+	var err error
+	switch valueTyped := value.(type) {
+	case json.Number:
+		f, err = valueTyped.Float64()
+	case string:
+		f, err = strconv.ParseFloat(strings.TrimSpace(valueTyped), 64)
+	case bool:
+		if valueTyped {
+			f = 1
+		}
+	default:
+		return nil, false
+	}
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	out, err := json.Marshal(f)
+	return out, err == nil
+}
+
+func castToString(value interface{}, raw []byte) ([]byte, bool) {
+	var text string
+	switch valueTyped := value.(type) {
+	case string:
+		return raw, true
+	case json.Number:
+		text = valueTyped.String()
+	case bool:
+		text = strconv.FormatBool(valueTyped)
+	default:
+		// objects and arrays are serialized as compact json text
+		var buffer bytes.Buffer
+		if err := json.Compact(&buffer, raw); err != nil {
+			return nil, false
+		}
+		text = buffer.String()
+	}
+	out, err := encodeJSON(text)
+	return out, err == nil
+}
+
+// castToBool converts booleans, strings and numbers to booleans. With a `true` list,
+// listed values are true and, with a `false` list, listed values are false and any
+// other value cannot be converted. With only a `true` list, every unlisted value,
+// including a typo such as "yse", is false.
+func (c *castSpec) castToBool(value interface{}) ([]byte, bool) {
+	var text string
+	switch valueTyped := value.(type) {
+	case bool:
+		return []byte(strconv.FormatBool(valueTyped)), true
+	case string:
+		text = strings.TrimSpace(valueTyped)
+	case json.Number:
+		text = valueTyped.String()
+	default:
+		return nil, false
+	}
+
+	if c.trueValues != nil {
+		if matchesAny(text, c.trueValues) {
+			return []byte("true"), true
+		}
+		// without an explicit false list, anything that isn't true is false
+		if c.falseValues == nil || matchesAny(text, c.falseValues) {
+			return []byte("false"), true
+		}
+		return nil, false
+	}
+
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		if err != nil {
+			return nil, false
+		}
+		return []byte(strconv.FormatBool(f != 0)), true
+	}
+	b, err := strconv.ParseBool(text)
+	if err != nil {
+		return nil, false
+	}
+	return []byte(strconv.FormatBool(b)), true
+}
+
+// matchesAny reports whether text case-insensitively equals one of values.
+func matchesAny(text string, values []string) bool {
+	for _, v := range values {
+		if strings.EqualFold(text, v) {
+			return true
+		}
+	}
+	return false
+}
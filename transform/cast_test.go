@@ -0,0 +1,183 @@
+package transform
+
+import "testing"
+
+func TestCast(t *testing.T) {
+	spec := `{"age": "int", "price": "float", "zip": "string", "active": {"type": "bool", "true": ["Y", "yes"]}, "verified": "bool"}`
+	jsonIn := `{"age":"42","price":"19.99","zip":2134,"active":"Y","verified":"false"}`
+	jsonOut := `{"age":42,"price":19.99,"zip":"2134","active":true,"verified":false}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Cast, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error in transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestCastWithWildcard(t *testing.T) {
+	spec := `{"items[*].qty": "int", "items[*].tags[*]": "string"}`
+	jsonIn := `{"items":[{"qty":"1","tags":[1,true]},{"qty":2.0,"tags":[]},{"qty":"3"}]}`
+	jsonOut := `{"items":[{"qty":1,"tags":["1","true"]},{"qty":2,"tags":[]},{"qty":3}]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Cast, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error in transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestCastKeepsLargeIntegers(t *testing.T) {
+	spec := `{"id": "int", "idString": "string"}`
+	jsonIn := `{"id":"9007199254740993","idString":9007199254740993}`
+	jsonOut := `{"id":9007199254740993,"idString":"9007199254740993"}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, _ := getTransformTestWrapper(Cast, cfg, jsonIn)
+
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestCastImpossibleConversion(t *testing.T) {
+	spec := `{"age": "int", "active": {"type": "bool", "true": ["Y"], "false": ["N"]}}`
+	jsonIn := `{"age":"unknown","active":"maybe"}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Cast, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Unconvertible values should be kept without require.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonIn))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonIn)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+
+	cfg = getConfig(spec, true)
+	_, err = getTransformTestWrapper(Cast, cfg, jsonIn)
+	if _, ok := err.(ParseError); !ok {
+		t.Error("Unconvertible values should throw a ParseError with require.")
+		t.Log("Error: ", err)
+		t.FailNow()
+	}
+}
+
+func TestCastIntRejectsFractions(t *testing.T) {
+	spec := `{"a": "int", "b": "int", "c": "int", "d": "int"}`
+	jsonIn := `{"a":3.7,"b":"-0.5","c":"1e3","d":2.0}`
+	jsonOut := `{"a":3.7,"b":"-0.5","c":1000,"d":2}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, _ := getTransformTestWrapper(Cast, cfg, jsonIn)
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+
+	cfg = getConfig(`{"a": "int"}`, true)
+	_, err := getTransformTestWrapper(Cast, cfg, jsonIn)
+	if _, ok := err.(ParseError); !ok {
+		t.Error("A fractional value should throw a ParseError with require.")
+		t.Log("Error: ", err)
+	}
+}
+
+func TestCastBoolValueLists(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		jsonOut string
+	}{
+		// with only a true list, every unlisted value is false
+		{`{"type": "bool", "true": ["Y", "yes"]}`, `[true,true,false,false,false]`},
+		// with both lists, unlisted values are kept
+		{`{"type": "bool", "true": ["Y", "yes"], "false": ["N", "no"]}`, `[true,true,false,"yse",1]`},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(`{"flags[*]": `+tc.spec+`}`, false)
+		kazaamOut, err := getTransformTestWrapper(Cast, cfg, `{"flags":["y","yes","N","yse",1]}`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		jsonOut := `{"flags":` + tc.jsonOut + `}`
+		if string(kazaamOut) != jsonOut {
+			t.Error("Transformed data does not match expectation.")
+			t.Log("Expected:   ", jsonOut)
+			t.Log("Actual:     ", string(kazaamOut))
+		}
+	}
+}
+
+func TestCastMissingPathRequire(t *testing.T) {
+	testCases := []string{
+		`{"missing": "int"}`,
+		`{"missing[*].value": "int"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, true)
+		_, err := getTransformTestWrapper(Cast, cfg, testJSONInput)
+		if err != NonExistentPath {
+			t.Error("Transform path does not exist in message and should throw an error")
+			t.Log("Spec:       ", spec)
+			t.Log("Error:      ", err)
+		}
+	}
+}
+
+func TestCastInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"age": "integer128"}`,
+		`{"age": 4}`,
+		`{"age": {"true": ["Y"]}}`,
+		`{"age": {"type": "bool", "true": "Y"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		_, err := getTransformTestWrapper(Cast, cfg, testJSONInput)
+		if _, ok := err.(SpecError); !ok {
+			t.Error("Should have thrown a SpecError for an invalid cast spec.")
+			t.Log("Spec:       ", spec)
+			t.Log("Error:      ", err)
+		}
+		cfg = getConfig(spec, false)
+		if err := PrepareCast(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError preparing an invalid cast spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// datetimeSpec describes the date and time function computed for a single target
+// path.
+type datetimeSpec struct {
+	fn   string
+	path string
+	// from is the start time of `diff`
+	from   string
+	offset datetimeOffset
+	unit   string
+	ts     *timestampSpec
+}
+
+// datetimeOffset is a signed offset in calendar units and a duration.
+type datetimeOffset struct {
+	years, months, days int
+	duration            time.Duration
+}
+
+// datetimeFuncs computes the result of a date and time function, as raw json. The
+// time is in the output time zone when one is set.
+var datetimeFuncs = map[string]func(d *datetimeSpec, t time.Time, from time.Time) ([]byte, error){
+	"add": func(d *datetimeSpec, t time.Time, from time.Time) ([]byte, error) {
+		return []byte(d.ts.format(d.offset.addTo(t))), nil
+	},
+	"truncate": func(d *datetimeSpec, t time.Time, from time.Time) ([]byte, error) {
+		return []byte(d.ts.format(truncateTime(t, d.unit))), nil
+	},
+	"diff": func(d *datetimeSpec, t time.Time, from time.Time) ([]byte, error) {
+		return encodeExprValue(durationIn(t.Sub(from), datetimeDiffUnits[d.unit]))
+	},
+	"weekday": func(d *datetimeSpec, t time.Time, from time.Time) ([]byte, error) {
+		return encodeJSON(t.Weekday().String())
+	},
+	"isoWeek": func(d *datetimeSpec, t time.Time, from time.Time) ([]byte, error) {
+		year, week := t.ISOWeek()
+		return encodeJSON(fmt.Sprintf("%04d-W%02d", year, week))
+	},
+}
+
+// datetimeTruncateUnits are the units times can be truncated to.
+var datetimeTruncateUnits = map[string]bool{
+	"second": true,
+	"minute": true,
+	"hour":   true,
+	"day":    true,
+	"week":   true,
+	"month":  true,
+	"year":   true,
+}
+
+// datetimeDiffUnits are the units of differences between times. Days and weeks are
+// always 24 and 168 hours long.
+var datetimeDiffUnits = map[string]time.Duration{
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         24 * time.Hour,
+	"week":        7 * 24 * time.Hour,
+}
+
+// datetimeOffsetUnits are the units of offsets, longest first so that `mo` and `ms`
+// are not read as minutes.
+var datetimeOffsetUnits = []struct {
+	name string
+	add  func(o *datetimeOffset, n int64)
+}{
+	{"mo", func(o *datetimeOffset, n int64) { o.months += int(n) }},
+	{"ms", func(o *datetimeOffset, n int64) { o.duration += time.Duration(n) * time.Millisecond }},
+	{"us", func(o *datetimeOffset, n int64) { o.duration += time.Duration(n) * time.Microsecond }},
+	{"ns", func(o *datetimeOffset, n int64) { o.duration += time.Duration(n) }},
+	{"y", func(o *datetimeOffset, n int64) { o.years += int(n) }},
+	{"w", func(o *datetimeOffset, n int64) { o.days += 7 * int(n) }},
+	{"d", func(o *datetimeOffset, n int64) { o.days += int(n) }},
+	{"h", func(o *datetimeOffset, n int64) { o.duration += time.Duration(n) * time.Hour }},
+	{"m", func(o *datetimeOffset, n int64) { o.duration += time.Duration(n) * time.Minute }},
+	{"s", func(o *datetimeOffset, n int64) { o.duration += time.Duration(n) * time.Second }},
+}
+
+// Datetime sets each target path to the result of a date and time function of the
+// time at a source path, the target itself by default, or `$now` for the current
+// time: an offset such as `30d`, the start of its hour or day, the difference from
+// another time, its weekday or its ISO week. Times are parsed and formatted as by
+// Timestamp.
+func Datetime(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseDatetimeSpecs)
+	if err != nil {
+		return nil, err
+	}
+	// every result is computed from the input data, before any result is set
+	var results []exprResult
+	for k, d := range parsed.(map[string]*datetimeSpec) {
+		targets, err := expandWildcards(data, k, false, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			// wildcards shared with the target refer to the same elements
+			t, ok, err := d.timeAt(spec, data, fillWildcards(d.path, target.indexes))
+			if err != nil {
+				return nil, err
+			}
+			var from time.Time
+			if ok && d.fn == "diff" {
+				from, ok, err = d.timeAt(spec, data, fillWildcards(d.from, target.indexes))
+				if err != nil {
+					return nil, err
+				}
+			}
+			// a missing time leaves the target unset
+			if !ok {
+				continue
+			}
+			if d.ts.outputLocation != nil {
+				t = t.In(d.ts.outputLocation)
+			}
+			value, err := datetimeFuncs[d.fn](d, t, from)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, exprResult{path: target.path, value: value})
+		}
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareDatetime parses the datetime spec, loading its time zones, ahead of
+// transforming any data.
+func PrepareDatetime(spec *Config) error {
+	return spec.prepareWith(parseDatetimeSpecs)
+}
+
+func parseDatetimeSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*datetimeSpec)
+	for k, v := range *spec.Spec {
+		datetimeMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		d := &datetimeSpec{path: k}
+		if d.fn, ok = datetimeMap["fn"].(string); !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"fn\" for key: %s", k))
+		}
+		if err := d.parseOptions(datetimeMap); err != nil {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Datetime function %s %v for key: %s", d.fn, err, k))
+		}
+		var err error
+		if d.ts, err = newTimestampSpec(datetimeMap, k, false); err != nil {
+			return nil, err
+		}
+		if d.ts.inputFormats[0] == nowFormat {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"$now\" is a path rather than an input format for key: %s", k))
+		}
+		for _, path := range []string{d.path, d.from} {
+			if strings.Count(path, "[*]") > strings.Count(k, "[*]") {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Path %s has more wildcards than the target for key: %s", path, k))
+			}
+		}
+		specs[k] = d
+	}
+	return specs, nil
+}
+
+// parseOptions parses the options of the datetime function.
+func (d *datetimeSpec) parseOptions(options map[string]interface{}) error {
+	formatOptions := []string{"path", "inputFormat", "outputFormat", "inputTimezone", "outputTimezone"}
+	var err error
+	switch d.fn {
+	case "add":
+		if err = requireOptions(options, []string{"offset"}, formatOptions...); err != nil {
+			return err
+		}
+		var offset string
+		if offset, err = stringOption(options, "offset", ""); err != nil {
+			return err
+		}
+		if d.offset, err = parseDatetimeOffset(offset); err != nil {
+			return err
+		}
+	case "truncate":
+		if err = requireOptions(options, []string{"unit"}, formatOptions...); err != nil {
+			return err
+		}
+		if d.unit, err = stringOption(options, "unit", ""); err != nil {
+			return err
+		}
+		if !datetimeTruncateUnits[d.unit] {
+			return fmt.Errorf("has unknown unit %q", d.unit)
+		}
+	case "diff":
+		if err = requireOptions(options, []string{"from", "unit"}, formatOptions...); err != nil {
+			return err
+		}
+		if d.from, err = stringOption(options, "from", ""); err != nil {
+			return err
+		}
+		if d.unit, err = stringOption(options, "unit", ""); err != nil {
+			return err
+		}
+		if _, ok := datetimeDiffUnits[d.unit]; !ok {
+			return fmt.Errorf("has unknown unit %q", d.unit)
+		}
+	case "weekday", "isoWeek":
+		err = requireOptions(options, nil, formatOptions...)
+	default:
+		return fmt.Errorf("is unknown")
+	}
+	if err != nil {
+		return err
+	}
+	d.path, err = stringOption(options, "path", d.path)
+	return err
+}
+
+// parseDatetimeOffset parses an optionally signed offset made of integers followed by
+// a unit: `y`, `mo`, `w`, `d`, `h`, `m`, `s`, `ms`, `us` or `ns`, e.g. `-1d12h`.
+func parseDatetimeOffset(s string) (datetimeOffset, error) {
+	var offset datetimeOffset
+	rest := strings.TrimLeft(s, "+-")
+	if len(s)-len(rest) > 1 || rest == "" {
+		return offset, fmt.Errorf("has invalid offset %q", s)
+	}
+	for rest != "" {
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		n, err := strconv.ParseInt(rest[:digits], 10, 32)
+		if err != nil {
+			return offset, fmt.Errorf("has invalid offset %q", s)
+		}
+		if s[0] == '-' {
+			n = -n
+		}
+		rest = rest[digits:]
+		found := false
+		for _, unit := range datetimeOffsetUnits {
+			if strings.HasPrefix(rest, unit.name) {
+				unit.add(&offset, n)
+				rest, found = rest[len(unit.name):], true
+				break
+			}
+		}
+		if !found {
+			return offset, fmt.Errorf("has invalid offset %q", s)
+		}
+	}
+	return offset, nil
+}
+
+// addTo adds the calendar units of the offset, then its duration, to t.
+func (o datetimeOffset) addTo(t time.Time) time.Time {
+	return t.AddDate(o.years, o.months, o.days).Add(o.duration)
+}
+
+// timeAt returns the time at path, or the current time for the `$now` path. It
+// returns false when the path is missing or null.
+func (d *datetimeSpec) timeAt(spec *Config, data []byte, path string) (time.Time, bool, error) {
+	if path == nowFormat {
+		return spec.now(), true, nil
+	}
+	value, err := getJSONRaw(data, path, spec.Require, spec.KeySeparator)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if string(value) == "null" {
+		return time.Time{}, false, nil
+	}
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	t, err := d.ts.parseValue(decoded)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+// truncateTime returns the start of the unit of t in its time zone. Weeks start on
+// Monday.
+func truncateTime(t time.Time, unit string) time.Time {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	switch unit {
+	case "year":
+		month, day, hour, minute, second = time.January, 1, 0, 0, 0
+	case "month":
+		day, hour, minute, second = 1, 0, 0, 0
+	case "week":
+		day, hour, minute, second = day-(int(t.Weekday())+6)%7, 0, 0, 0
+	case "day":
+		hour, minute, second = 0, 0, 0
+	case "hour":
+		minute, second = 0, 0
+	case "minute":
+		second = 0
+	}
+	return time.Date(year, month, day, hour, minute, second, 0, t.Location())
+}
+
+// durationIn returns d in units, as an integer when it is a whole number of units.
+func durationIn(d time.Duration, unit time.Duration) interface{} {
+	if d%unit == 0 {
+		return int64(d / unit)
+	}
+	return float64(d) / float64(unit)
+}
@@ -0,0 +1,119 @@
+package transform
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatetime(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"add days", `{"expiresAt": {"fn": "add", "path": "createdAt", "offset": "30d"}}`, `{"createdAt":"2020-01-15T10:00:00Z"}`, `{"createdAt":"2020-01-15T10:00:00Z","expiresAt":"2020-02-14T10:00:00Z"}`},
+		{"add calendar units and duration", `{"t": {"fn": "add", "offset": "1y1mo2h30m"}}`, `{"t":"2020-01-31T10:00:00Z"}`, `{"t":"2021-03-03T12:30:00Z"}`},
+		{"subtract", `{"t": {"fn": "add", "offset": "-1w12h"}}`, `{"t":"2020-01-15T10:00:00Z"}`, `{"t":"2020-01-07T22:00:00Z"}`},
+		{"add milliseconds to an epoch", `{"t": {"fn": "add", "offset": "1500ms", "inputFormat": "$unixms"}}`, `{"t":1500621327000}`, `{"t":"1500621328500"}`},
+		{"add keeps local time across DST", `{"t": {"fn": "add", "offset": "1d", "outputTimezone": "Europe/Paris"}}`, `{"t":"2020-03-28T12:00:00+01:00"}`, `{"t":"2020-03-29T12:00:00+02:00"}`},
+		{"truncate to hour", `{"events[*].hour": {"fn": "truncate", "path": "events[*].at", "unit": "hour"}}`, `{"events":[{"at":"2020-01-15T10:42:13Z"},{"at":"2020-01-15T11:05:00Z"}]}`, `{"events":[{"at":"2020-01-15T10:42:13Z","hour":"2020-01-15T10:00:00Z"},{"at":"2020-01-15T11:05:00Z","hour":"2020-01-15T11:00:00Z"}]}`},
+		{"truncate to hour with a half hour offset", `{"t": {"fn": "truncate", "unit": "hour"}}`, `{"t":"2020-01-15T10:42:13+05:30"}`, `{"t":"2020-01-15T10:00:00+05:30"}`},
+		{"truncate to day in a time zone", `{"day": {"fn": "truncate", "path": "t", "unit": "day", "outputTimezone": "America/New_York", "outputFormat": "2006-01-02T15:04:05Z07:00"}}`, `{"t":"2020-01-15T03:00:00Z"}`, `{"t":"2020-01-15T03:00:00Z","day":"2020-01-14T00:00:00-05:00"}`},
+		{"truncate to week", `{"t": {"fn": "truncate", "unit": "week", "inputFormat": "2006-01-02", "outputFormat": "2006-01-02"}}`, `{"t":"2020-01-05"}`, `{"t":"2019-12-30"}`},
+		{"truncate to month and year", `{"m": {"fn": "truncate", "path": "t", "unit": "month"}, "y": {"fn": "truncate", "path": "t", "unit": "year"}}`, `{"t":"2020-05-15T10:42:13Z"}`, `{"t":"2020-05-15T10:42:13Z","m":"2020-05-01T00:00:00Z","y":"2020-01-01T00:00:00Z"}`},
+		{"diff in hours", `{"hours": {"fn": "diff", "path": "end", "from": "start", "unit": "hour"}}`, `{"start":"2020-01-15T10:00:00Z","end":"2020-01-15T13:30:00Z"}`, `{"start":"2020-01-15T10:00:00Z","end":"2020-01-15T13:30:00Z","hours":3.5}`},
+		{"diff in days across zones", `{"days": {"fn": "diff", "path": "end", "from": "start", "unit": "day"}}`, `{"start":"2020-01-15T10:00:00+02:00","end":"2020-01-17T08:00:00Z"}`, `{"start":"2020-01-15T10:00:00+02:00","end":"2020-01-17T08:00:00Z","days":2}`},
+		{"negative diff of epochs", `{"items[*].ms": {"fn": "diff", "path": "items[*].end", "from": "items[*].start", "unit": "millisecond", "inputFormat": "$unixms"}}`, `{"items":[{"start":1500,"end":1000}]}`, `{"items":[{"start":1500,"end":1000,"ms":-500}]}`},
+		{"weekday", `{"day": {"fn": "weekday", "path": "t"}}`, `{"t":"2020-01-15T10:00:00Z"}`, `{"t":"2020-01-15T10:00:00Z","day":"Wednesday"}`},
+		{"weekday in a time zone", `{"day": {"fn": "weekday", "path": "t", "outputTimezone": "Asia/Tokyo"}}`, `{"t":"2020-01-15T20:00:00Z"}`, `{"t":"2020-01-15T20:00:00Z","day":"Thursday"}`},
+		{"ISO week", `{"week": {"fn": "isoWeek", "path": "t", "inputFormat": "2006-01-02"}}`, `{"t":"2021-01-01"}`, `{"t":"2021-01-01","week":"2020-W53"}`},
+		{"fallback input formats", `{"t[*]": {"fn": "add", "offset": "1d", "inputFormat": ["2006-01-02", "02/01/2006"], "outputFormat": "2006-01-02"}}`, `{"t":["2020-01-15","31/12/2020"]}`, `{"t":["2020-01-16","2021-01-01"]}`},
+		{"missing times are skipped", `{"a": {"fn": "add", "offset": "1d"}, "b": {"fn": "diff", "path": "end", "from": "start", "unit": "second"}}`, `{"a":null,"end":"2020-01-15T10:00:00Z"}`, `{"a":null,"end":"2020-01-15T10:00:00Z"}`},
+		{"results use the input data", `{"t": {"fn": "add", "offset": "1h"}, "u": {"fn": "add", "path": "t", "offset": "1h"}}`, `{"t":"2020-01-15T10:00:00Z"}`, `{"t":"2020-01-15T11:00:00Z","u":"2020-01-15T11:00:00Z"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Datetime, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(tc.want))
+			if !areEqual {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestDatetimeWithNow(t *testing.T) {
+	spec := `{"expiresAt": {"fn": "add", "path": "$now", "offset": "30d"}, "age": {"fn": "diff", "path": "$now", "from": "born", "unit": "day"}, "left": {"fn": "diff", "path": "due", "from": "$now", "unit": "hour"}}`
+	jsonIn := `{"born":"2020-01-05T10:00:00Z","due":"2020-01-16T12:00:00Z"}`
+	jsonOut := `{"born":"2020-01-05T10:00:00Z","due":"2020-01-16T12:00:00Z","expiresAt":"2020-02-14T10:00:00Z","age":10,"left":26}`
+
+	cfg := getConfig(spec, false)
+	cfg.Clock = func() time.Time { return time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC) }
+	kazaamOut, _ := getTransformTestWrapper(Datetime, cfg, jsonIn)
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestDatetimeErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		require bool
+		in      string
+	}{
+		{"unparseable time", `{"t": {"fn": "add", "offset": "1d"}}`, false, `{"t":"yesterday"}`},
+		{"unparseable diff start", `{"d": {"fn": "diff", "path": "end", "from": "start", "unit": "day"}}`, false, `{"start":true,"end":"2020-01-15T10:00:00Z"}`},
+		{"required path", `{"t": {"fn": "weekday"}}`, true, `{}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, tc.require)
+			if _, err := getTransformTestWrapper(Datetime, cfg, tc.in); err == nil {
+				t.Error("Should have thrown an error.")
+			}
+		})
+	}
+}
+
+func TestDatetimeInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"t": 1}`,
+		`{"t": {"offset": "1d"}}`,
+		`{"t": {"fn": "subtract", "offset": "1d"}}`,
+		`{"t": {"fn": "add"}}`,
+		`{"t": {"fn": "add", "offset": 1}}`,
+		`{"t": {"fn": "add", "offset": "1"}}`,
+		`{"t": {"fn": "add", "offset": "d"}}`,
+		`{"t": {"fn": "add", "offset": "--1d"}}`,
+		`{"t": {"fn": "add", "offset": "1d2x"}}`,
+		`{"t": {"fn": "add", "offset": "1d", "unit": "day"}}`,
+		`{"t": {"fn": "truncate", "unit": "fortnight"}}`,
+		`{"t": {"fn": "diff", "unit": "day"}}`,
+		`{"t": {"fn": "diff", "from": "start", "unit": "month"}}`,
+		`{"t": {"fn": "weekday", "path": 1}}`,
+		`{"t": {"fn": "weekday", "inputFormat": "$now"}}`,
+		`{"t": {"fn": "weekday", "outputTimezone": "Nowhere/Land"}}`,
+		`{"t": {"fn": "weekday", "path": "items[*].at"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareDatetime(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid datetime spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
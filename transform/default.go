@@ -1,22 +1,206 @@
 package transform
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
-// Default sets specific value(s) in output json in raw []byte.
+// exprKey marks a computed default value, e.g. `{"$expr": "firstName + ' ' + lastName"}`.
+const exprKey = "$expr"
+
+// modeKey sets how the default values are written, see the default modes.
+const modeKey = "$mode"
+
+// default modes
+const (
+	// defaultModeSet always sets the values, overwriting existing ones
+	defaultModeSet = "set"
+	// defaultModeIfAbsent only sets the values at paths that are missing or null
+	defaultModeIfAbsent = "ifAbsent"
+	// defaultModeMerge deep-merges default objects into existing ones, filling in
+	// their missing or null fields
+	defaultModeMerge = "merge"
+)
+
+// defaultSpec holds the values of a default spec, either raw json or computed.
+type defaultSpec struct {
+	mode     string
+	values   map[string][]byte
+	computed map[string]*Expression
+}
+
+// Default sets specific value(s) in output json in raw []byte. A value of the form
+// `{"$expr": "..."}` is computed from the input data, see PrepareDefault. Targets with
+// `[*]` wildcards set the value on every array element, and the `$mode` key of the
+// spec allows only setting missing values, or merging default objects.
 func Default(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseDefaultSpecs)
+	if err != nil {
+		return nil, err
+	}
+	d := parsed.(*defaultSpec)
+
+	// computed values are evaluated against the input data, before any value is set
+	var computedResults []exprResult
+	for k, e := range d.computed {
+		targetResults, err := evalExprTarget(spec, data, k, e, nil)
+		if err != nil {
+			return nil, err
+		}
+		computedResults = append(computedResults, targetResults...)
+	}
+
+	var results []exprResult
+	for k, value := range d.values {
+		paths, err := expandWildcards(data, k, false, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			results = append(results, exprResult{path: p.path, value: value})
+		}
+	}
+	for _, result := range append(results, computedResults...) {
+		value, err := d.defaultValue(spec, data, result)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		data, err = setJSONRaw(data, value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareDefault parses the expressions of computed default values ahead of
+// transforming any data.
+func PrepareDefault(spec *Config) error {
+	return spec.prepareWith(parseDefaultSpecs)
+}
+
+func parseDefaultSpecs(spec *Config) (interface{}, error) {
+	d := &defaultSpec{mode: defaultModeSet, values: make(map[string][]byte), computed: make(map[string]*Expression)}
 	for k, v := range *spec.Spec {
-		var err error
+		if k == modeKey {
+			switch v {
+			case defaultModeSet, defaultModeIfAbsent, defaultModeMerge:
+				d.mode = v.(string)
+			default:
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown mode %v for key: %s", v, k))
+			}
+			continue
+		}
+		e, err := parseComputedDefault(k, v)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			d.computed[k] = e
+			continue
+		}
 		dataForV, err := json.Marshal(v)
 		if err != nil {
 			return nil, ParseError(fmt.Sprintf("Warn: Unable to coerce element to json string: %v", v))
 		}
-		data, err = setJSONRaw(data, dataForV, k, spec.KeySeparator)
+		d.values[k] = dataForV
+	}
+	return d, nil
+}
+
+// parseComputedDefault returns the expression of a computed default value, or nil for
+// any other value.
+func parseComputedDefault(k string, v interface{}) (*Expression, error) {
+	valueMap, ok := v.(map[string]interface{})
+	if !ok || len(valueMap) != 1 {
+		return nil, nil
+	}
+	exprInterface, ok := valueMap[exprKey]
+	if !ok {
+		return nil, nil
+	}
+	source, ok := exprInterface.(string)
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Expression must be a string for key: %s", k))
+	}
+	e, err := ParseExpression(source)
+	if err != nil {
+		return nil, SpecError(fmt.Sprintf("%v for key: %s", err, k))
+	}
+	return e, nil
+}
+
+// defaultValue returns the raw value to set at the path of the result in the mode of
+// the spec, or nil when the existing value is kept.
+func (d *defaultSpec) defaultValue(spec *Config, data []byte, result exprResult) ([]byte, error) {
+	// an appended array element is always absent
+	if d.mode == defaultModeSet || strings.Contains(result.path, "[+]") {
+		return result.value, nil
+	}
+	existing, err := getJSONRaw(data, result.path, false, spec.KeySeparator)
+	if err != nil {
+		return nil, err
+	}
+	if d.mode == defaultModeIfAbsent {
+		if string(existing) != "null" {
+			return nil, nil
+		}
+		return result.value, nil
+	}
+	merged, err := mergeDefault(existing, result.value)
+	if err != nil || bytes.Equal(merged, existing) {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// mergeDefault merges the raw default value into the existing one: a missing or null
+// existing value is replaced by the default, and objects are merged field by field,
+// keeping the order of the existing fields. Any other existing value is kept.
+func mergeDefault(existing, def []byte) ([]byte, error) {
+	if string(existing) == "null" {
+		return def, nil
+	}
+	if existing[0] != '{' || def[0] != '{' {
+		return existing, nil
+	}
+	fields, err := objectFields(existing)
+	if err != nil {
+		return nil, err
+	}
+	defFields, err := objectFields(def)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[string]int, len(fields))
+	for i, field := range fields {
+		indexes[field.key] = i
+	}
+	changed := false
+	for _, defField := range defFields {
+		i, ok := indexes[defField.key]
+		if !ok {
+			indexes[defField.key] = len(fields)
+			fields = append(fields, defField)
+			changed = true
+			continue
+		}
+		merged, err := mergeDefault(fields[i].value, defField.value)
 		if err != nil {
 			return nil, err
 		}
+		if !bytes.Equal(merged, fields[i].value) {
+			fields[i].value = merged
+			changed = true
+		}
 	}
-	return data, nil
+	if !changed {
+		return existing, nil
+	}
+	return joinObject(fields)
 }
@@ -46,3 +46,87 @@ func TestDefaultArrayAppend(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestDefaultComputed(t *testing.T) {
+	spec := `{"name": {"$expr": "first + ' ' + last"}, "items[*].total": {"$expr": "items[*].price * items[*].qty"}, "first": "Grace", "literal": {"$expr": 1, "other": 2}}`
+	jsonInput := `{"first":"Ada","last":"Lovelace","items":[{"price":2,"qty":3}]}`
+	jsonOut := `{"first":"Grace","last":"Lovelace","items":[{"price":2,"qty":3,"total":6}],"name":"Ada Lovelace","literal":{"$expr":1,"other":2}}`
+
+	cfg := getConfig(spec, false)
+	if err := PrepareDefault(&cfg); err != nil {
+		t.Fatalf("unexpected error preparing spec: %v", err)
+	}
+	kazaamOut, err := getTransformTestWrapper(Default, cfg, jsonInput)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestDefaultComputedInvalid(t *testing.T) {
+	testCases := []string{
+		`{"name": {"$expr": 1}}`,
+		`{"name": {"$expr": "first +"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareDefault(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid computed default.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestDefaultModes(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"set overwrites", `{"$mode": "set", "a": 1, "b": 2}`, `{"a":0,"b":null}`, `{"a":1,"b":2}`},
+		{"set on every element", `{"items[*].qty": 1}`, `{"items":[{"qty":3},{}]}`, `{"items":[{"qty":1},{"qty":1}]}`},
+		{"set without elements", `{"items[*].qty": 1}`, `{}`, `{}`},
+		{"if absent", `{"$mode": "ifAbsent", "a": 1, "b": 2, "c": 3, "d": {"x": 1}}`, `{"a":0,"b":null,"d":{"y":2}}`, `{"a":0,"b":2,"d":{"y":2},"c":3}`},
+		{"if absent on every element", `{"$mode": "ifAbsent", "items[*].qty": 1}`, `{"items":[{"qty":3},{"qty":null},{}]}`, `{"items":[{"qty":3},{"qty":1},{"qty":1}]}`},
+		{"if absent computed", `{"$mode": "ifAbsent", "name": {"$expr": "first + ' ' + last"}, "items[*].total": {"$expr": "items[*].price * 2"}}`, `{"first":"Ada","last":"Lovelace","name":"Countess","items":[{"price":2},{"price":3,"total":5}]}`, `{"first":"Ada","last":"Lovelace","name":"Countess","items":[{"price":2,"total":4},{"price":3,"total":5}]}`},
+		{"if absent append", `{"$mode": "ifAbsent", "tags[+]": "new"}`, `{"tags":["a"]}`, `{"tags":["a","new"]}`},
+		{"merge", `{"$mode": "merge", "settings": {"theme": "light", "notify": {"email": true, "sms": false}, "tags": ["x"]}}`, `{"settings":{"notify":{"sms":true,"push":null},"theme":"dark","tags":[]}}`, `{"settings":{"notify":{"sms":true,"push":null,"email":true},"theme":"dark","tags":[]}}`},
+		{"merge nulls", `{"$mode": "merge", "a": {"b": {"c": 1}}, "d": {"e": 1}}`, `{"a":{"b":null},"d":null}`, `{"a":{"b":{"c":1}},"d":{"e":1}}`},
+		{"merge keeps other values", `{"$mode": "merge", "a": {"b": 1}, "c": 2}`, `{"a":"text","c":3}`, `{"a":"text","c":3}`},
+		{"merge on every element", `{"$mode": "merge", "users[*].prefs": {"lang": "en", "tz": "UTC"}}`, `{"users":[{"prefs":{"lang":"fr"}},{}]}`, `{"users":[{"prefs":{"lang":"fr","tz":"UTC"}},{"prefs":{"lang":"en","tz":"UTC"}}]}`},
+		{"merge leaves complete objects untouched", `{"$mode": "merge", "a": {"b": 1}}`, `{"a":{ "b" : 2 }}`, `{"a":{ "b" : 2 }}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Default, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestDefaultInvalidMode(t *testing.T) {
+	for _, spec := range []string{`{"$mode": "replace", "a": 1}`, `{"$mode": 1, "a": 1}`} {
+		cfg := getConfig(spec, false)
+		if err := PrepareDefault(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid mode.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
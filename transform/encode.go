@@ -0,0 +1,231 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// encodings supported by the encode and decode transforms
+const (
+	encodingBase64     = "base64"
+	encodingBase64URL  = "base64url"
+	encodingHex        = "hex"
+	encodingURL        = "url"
+	encodingJSONString = "jsonString"
+)
+
+// encodeSpec describes the encodings applied to the value at a single path.
+type encodeSpec struct {
+	encodings  []string
+	padding    bool
+	targetPath string
+}
+
+// Encode encodes the value at each path with one or more encodings, applied in order,
+// writing the result in place or to `targetPath`. For example, `jsonString` followed
+// by `base64` turns an object into base64-encoded json.
+func Encode(spec *Config, data []byte) ([]byte, error) {
+	return codeValues(spec, data, (*encodeSpec).encode)
+}
+
+// PrepareEncode parses the encode spec ahead of transforming any data.
+func PrepareEncode(spec *Config) error {
+	return spec.prepareWith(parseEncodeSpecs)
+}
+
+// Decode is the reverse of Encode: it decodes the string at each path with one or more
+// encodings, applied in order. For example, `base64` followed by `jsonString` turns
+// base64-encoded json into an object.
+func Decode(spec *Config, data []byte) ([]byte, error) {
+	return codeValues(spec, data, (*encodeSpec).decode)
+}
+
+// PrepareDecode parses the decode spec ahead of transforming any data.
+func PrepareDecode(spec *Config) error {
+	return spec.prepareWith(parseEncodeSpecs)
+}
+
+// codeValues applies code to the value at each path, expanding its wildcards. Every
+// value is read from the input data before any result is set.
+func codeValues(spec *Config, data []byte, code func(e *encodeSpec, value []byte) ([]byte, error)) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseEncodeSpecs)
+	if err != nil {
+		return nil, err
+	}
+	var results []exprResult
+	for k, e := range parsed.(map[string]*encodeSpec) {
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			value, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			if string(value) == "null" {
+				continue
+			}
+			coded, err := code(e, value)
+			if err != nil {
+				return nil, ParseError(fmt.Sprintf("%v at %s", err, p.path))
+			}
+			targetPath := p.path
+			if e.targetPath != "" {
+				targetPath = fillWildcards(e.targetPath, p.indexes)
+			}
+			results = append(results, exprResult{path: targetPath, value: coded})
+		}
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func parseEncodeSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*encodeSpec)
+	for k, v := range *spec.Spec {
+		encodeMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		e := &encodeSpec{padding: true}
+		encodings, ok := encodeMap["encoding"]
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"encoding\" for key: %s", k))
+		}
+		encodingList, ok := encodings.([]interface{})
+		if !ok {
+			encodingList = []interface{}{encodings}
+		}
+		if len(encodingList) == 0 {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"encoding\" must not be empty for key: %s", k))
+		}
+		for _, encoding := range encodingList {
+			switch encoding {
+			case encodingBase64, encodingBase64URL, encodingHex, encodingURL, encodingJSONString:
+				e.encodings = append(e.encodings, encoding.(string))
+			default:
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown encoding %v for key: %s", encoding, k))
+			}
+		}
+		if padding, ok := encodeMap["padding"]; ok {
+			if e.padding, ok = padding.(bool); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"padding\" must be a boolean for key: %s", k))
+			}
+		}
+		if targetPath, ok := encodeMap["targetPath"]; ok {
+			if e.targetPath, ok = targetPath.(string); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"targetPath\" must be a string for key: %s", k))
+			}
+		}
+		specs[k] = e
+	}
+	return specs, nil
+}
+
+// encode returns the raw json string encoding value. Strings are encoded by their
+// content and numbers and booleans by their json text; arrays and objects must first
+// be encoded with `jsonString`.
+func (e *encodeSpec) encode(value []byte) ([]byte, error) {
+	for _, encoding := range e.encodings {
+		var content string
+		switch {
+		case encoding == encodingJSONString:
+			content = string(bytes.TrimSpace(value))
+		case value[0] == '"':
+			decoded, err := decodeJSON(value)
+			if err != nil {
+				return nil, err
+			}
+			content = e.encodeString(encoding, decoded.(string))
+		case value[0] == '[' || value[0] == '{':
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to encode %s as %s without \"jsonString\"", value, encoding))
+		default:
+			content = e.encodeString(encoding, string(value))
+		}
+		var err error
+		if value, err = encodeJSON(content); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+func (e *encodeSpec) encodeString(encoding, s string) string {
+	switch encoding {
+	case encodingBase64, encodingBase64URL:
+		return base64Encoding(encoding, e.padding).EncodeToString([]byte(s))
+	case encodingHex:
+		return hex.EncodeToString([]byte(s))
+	}
+	return url.QueryEscape(s)
+}
+
+// decode returns the raw json value decoded from the string value. Every encoding but
+// the last `jsonString` must decode to a string.
+func (e *encodeSpec) decode(value []byte) ([]byte, error) {
+	for _, encoding := range e.encodings {
+		if value[0] != '"' {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to decode non-string value %s as %s", value, encoding))
+		}
+		decoded, err := decodeJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		s := decoded.(string)
+		if encoding == encodingJSONString {
+			if !json.Valid([]byte(s)) {
+				return nil, ParseError(fmt.Sprintf("Warn: Unable to decode invalid json string %s", value))
+			}
+			value = bytes.TrimSpace([]byte(s))
+			continue
+		}
+		var content []byte
+		switch encoding {
+		case encodingBase64, encodingBase64URL:
+			// padding is optional when decoding
+			content, err = base64Encoding(encoding, false).DecodeString(strings.TrimRight(s, "="))
+		case encodingHex:
+			content, err = hex.DecodeString(s)
+		default:
+			var unescaped string
+			unescaped, err = url.QueryUnescape(s)
+			content = []byte(unescaped)
+		}
+		if err != nil {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to decode %s as %s: %v", value, encoding, err))
+		}
+		if !utf8.Valid(content) {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to decode %s as %s: result is not valid UTF-8", value, encoding))
+		}
+		if value, err = encodeJSON(string(content)); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// base64Encoding returns the standard or URL-safe base64 encoding, with or without
+// padding.
+func base64Encoding(encoding string, padding bool) *base64.Encoding {
+	switch {
+	case encoding == encodingBase64URL && padding:
+		return base64.URLEncoding
+	case encoding == encodingBase64URL:
+		return base64.RawURLEncoding
+	case padding:
+		return base64.StdEncoding
+	}
+	return base64.RawStdEncoding
+}
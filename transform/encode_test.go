@@ -0,0 +1,122 @@
+package transform
+
+import "testing"
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"base64", `{"s": {"encoding": "base64"}}`, `{"s":"hello world?"}`, `{"s":"aGVsbG8gd29ybGQ/"}`},
+		{"base64url", `{"s": {"encoding": "base64url"}}`, `{"s":"hello world?"}`, `{"s":"aGVsbG8gd29ybGQ_"}`},
+		{"base64 without padding", `{"s": {"encoding": "base64", "padding": false}}`, `{"s":"ab"}`, `{"s":"YWI"}`},
+		{"hex", `{"s": {"encoding": "hex"}}`, `{"s":"ab"}`, `{"s":"6162"}`},
+		{"url", `{"s": {"encoding": "url"}}`, `{"s":"a b&c=d/é"}`, `{"s":"a+b%26c%3Dd%2F%C3%A9"}`},
+		{"numbers are encoded by their text", `{"n": {"encoding": "hex"}}`, `{"n":12}`, `{"n":"3132"}`},
+		{"jsonString of an object", `{"o": {"encoding": "jsonString"}}`, `{"o":{"a":1,"b":"<x>"}}`, `{"o":"{\"a\":1,\"b\":\"<x>\"}"}`},
+		{"jsonString of a string", `{"s": {"encoding": "jsonString"}}`, `{"s":"x"}`, `{"s":"\"x\""}`},
+		{"encodings apply in order", `{"o": {"encoding": ["jsonString", "base64"]}}`, `{"o":{"a":1}}`, `{"o":"eyJhIjoxfQ=="}`},
+		{"target path", `{"o": {"encoding": ["jsonString", "base64"], "targetPath": "b64"}}`, `{"o":{"a":1}}`, `{"o":{"a":1},"b64":"eyJhIjoxfQ=="}`},
+		{"wildcards", `{"items[*].id": {"encoding": "hex", "targetPath": "items[*].hex"}}`, `{"items":[{"id":"ab"},{"id":"a"}]}`, `{"items":[{"id":"ab","hex":"6162"},{"id":"a","hex":"61"}]}`},
+		{"null and missing values are left unchanged", `{"a": {"encoding": "hex"}, "b": {"encoding": "hex"}}`, `{"a":null}`, `{"a":null}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Encode, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"base64", `{"s": {"encoding": "base64"}}`, `{"s":"aGVsbG8gd29ybGQ/"}`, `{"s":"hello world?"}`},
+		{"base64url", `{"s": {"encoding": "base64url"}}`, `{"s":"aGVsbG8gd29ybGQ_"}`, `{"s":"hello world?"}`},
+		{"padding is optional", `{"s": {"encoding": "base64"}, "t": {"encoding": "base64"}}`, `{"s":"YWI","t":"YWI="}`, `{"s":"ab","t":"ab"}`},
+		{"hex", `{"s": {"encoding": "hex"}}`, `{"s":"6162"}`, `{"s":"ab"}`},
+		{"url", `{"s": {"encoding": "url"}}`, `{"s":"a+b%26c%3Dd%2F%C3%A9"}`, `{"s":"a b&c=d/é"}`},
+		{"jsonString", `{"s": {"encoding": "jsonString"}}`, `{"s":"{\"b\":2, \"a\":[1,2]}"}`, `{"s":{"b":2, "a":[1,2]}}`},
+		{"encodings apply in order", `{"s": {"encoding": ["base64", "jsonString"]}}`, `{"s":"eyJhIjoxfQ=="}`, `{"s":{"a":1}}`},
+		{"wildcards with target path", `{"events[*].body": {"encoding": ["base64", "jsonString"], "targetPath": "events[*].payload"}}`, `{"events":[{"body":"eyJhIjoxfQ"}]}`, `{"events":[{"body":"eyJhIjoxfQ","payload":{"a":1}}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Decode, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeErrors(t *testing.T) {
+	testCases := []struct {
+		fn      func(spec *Config, data []byte) ([]byte, error)
+		spec    string
+		require bool
+	}{
+		{Encode, `{"o": {"encoding": "base64"}}`, false},
+		{Encode, `{"missing": {"encoding": "base64"}}`, true},
+		{Decode, `{"n": {"encoding": "hex"}}`, false},
+		{Decode, `{"s": {"encoding": "base64"}}`, false},
+		{Decode, `{"s": {"encoding": "jsonString"}}`, false},
+		{Decode, `{"bin": {"encoding": "hex"}}`, false},
+		{Decode, `{"s": {"encoding": ["jsonString", "hex"]}}`, false},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, tc.require)
+		_, err := getTransformTestWrapper(tc.fn, cfg, `{"o":{"a":1},"n":1,"s":"not base64!","bin":"ff"}`)
+		if err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Spec:       ", tc.spec)
+		}
+	}
+}
+
+func TestEncodeInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"a": "base64"}`,
+		`{"a": {}}`,
+		`{"a": {"encoding": []}}`,
+		`{"a": {"encoding": "base32"}}`,
+		`{"a": {"encoding": ["hex", 1]}}`,
+		`{"a": {"encoding": "base64", "padding": "no"}}`,
+		`{"a": {"encoding": "base64", "targetPath": 1}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareEncode(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid encode spec.")
+			t.Log("Spec:       ", spec)
+		}
+		if err := PrepareDecode(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid decode spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// entriesSpec describes the object to turn into an array of records.
+type entriesSpec struct {
+	path       string
+	targetPath string
+	keyName    string
+	valueName  string
+}
+
+// Entries turns an object into an array of `{"key": ..., "value": ...}` records, one
+// per field, in order: the reverse direction of keyBy.
+func Entries(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseEntriesSpec)
+	if err != nil {
+		return nil, err
+	}
+	e := parsed.(*entriesSpec)
+	return transformObjects(spec, data, e.path, e.targetPath, e.entries)
+}
+
+// PrepareEntries parses the entries spec ahead of transforming any data.
+func PrepareEntries(spec *Config) error {
+	return spec.prepareWith(parseEntriesSpec)
+}
+
+func parseEntriesSpec(spec *Config) (interface{}, error) {
+	e := &entriesSpec{keyName: "key", valueName: "value"}
+	fields := map[string]*string{"targetPath": &e.targetPath, "keyName": &e.keyName, "valueName": &e.valueName}
+	var ok bool
+	if e.path, ok = (*spec.Spec)["path"].(string); !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"path\"")
+	}
+	for name, field := range fields {
+		if v, ok := (*spec.Spec)[name]; ok {
+			if *field, ok = v.(string); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a string", name))
+			}
+		}
+	}
+	if e.keyName == e.valueName {
+		return nil, SpecError("Warn: Invalid spec. \"keyName\" and \"valueName\" must differ")
+	}
+	return e, nil
+}
+
+func (e *entriesSpec) entries(object []byte) ([]byte, error) {
+	fields, err := objectFields(object)
+	if err != nil {
+		return nil, err
+	}
+	records := make([][]byte, len(fields))
+	for i, field := range fields {
+		encodedKey, err := encodeJSON(field.key)
+		if err != nil {
+			return nil, err
+		}
+		if records[i], err = joinObject([]rawField{{key: e.keyName, value: encodedKey}, {key: e.valueName, value: field.value}}); err != nil {
+			return nil, err
+		}
+	}
+	return joinArray(records), nil
+}
@@ -0,0 +1,68 @@
+package transform
+
+import "testing"
+
+func TestEntries(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonIn  string
+		jsonOut string
+	}{
+		{
+			"default names in place",
+			`{"path": "attrs"}`,
+			`{"attrs":{"color":"red","size":10,"tags":["a"],"a\"b":null}}`,
+			`{"attrs":[{"key":"color","value":"red"},{"key":"size","value":10},{"key":"tags","value":["a"]},{"key":"a\"b","value":null}]}`,
+		},
+		{
+			"custom names to target path",
+			`{"path": "attrs", "targetPath": "list", "keyName": "name", "valueName": "v"}`,
+			`{"attrs":{"color":"red"}}`,
+			`{"attrs":{"color":"red"},"list":[{"name":"color","v":"red"}]}`,
+		},
+		{
+			"whole document",
+			`{"path": "$"}`,
+			`{"a":1,"b":{"c":2}}`,
+			`[{"key":"a","value":1},{"key":"b","value":{"c":2}}]`,
+		},
+		{
+			"nested objects under wildcards",
+			`{"path": "users[*].meta"}`,
+			`{"users":[{"meta":{"x":1}},{"meta":"none"}]}`,
+			`{"users":[{"meta":[{"key":"x","value":1}]},{"meta":"none"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Entries, cfg, tc.jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestEntriesInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"keyName": "k"}`,
+		`{"path": "a", "keyName": 1}`,
+		`{"path": "a", "valueName": "key"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareEntries(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid entries spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// exprResult is the encoded result of an expression for a concrete target path.
+type exprResult struct {
+	path  string
+	value []byte
+}
+
+// Expr sets each target path to the result of an expression, which may be of any
+// type, e.g. `{"label": "upper(name) + ' (' + string(age) + ')'"}`. Expressions are
+// parsed once per spec, see PrepareExpr.
+func Expr(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseExprSpecs)
+	if err != nil {
+		return nil, err
+	}
+	return setExprTargets(spec, data, parsed.(map[string]*Expression), nil)
+}
+
+// PrepareExpr parses the expressions of the expr spec ahead of transforming any data.
+func PrepareExpr(spec *Config) error {
+	return spec.prepareWith(parseExprSpecs)
+}
+
+// parseExprSpecs parses a spec mapping target paths to expressions.
+func parseExprSpecs(spec *Config) (interface{}, error) {
+	exprs := make(map[string]*Expression)
+	for k, v := range *spec.Spec {
+		source, ok := v.(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Expression must be a string for key: %s", k))
+		}
+		e, err := ParseExpression(source)
+		if err != nil {
+			return nil, SpecError(fmt.Sprintf("%v for key: %s", err, k))
+		}
+		exprs[k] = e
+	}
+	return exprs, nil
+}
+
+// setExprTargets evaluates the expression for each target path and sets the results.
+// Every expression is evaluated against the input data before any result is set, so
+// the outcome does not depend on the order of the targets. check, if not nil,
+// validates each result.
+func setExprTargets(spec *Config, data []byte, exprs map[string]*Expression, check func(target string, result interface{}) error) ([]byte, error) {
+	var results []exprResult
+	for target, e := range exprs {
+		targetResults, err := evalExprTarget(spec, data, target, e, check)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, targetResults...)
+	}
+	var err error
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// evalExprTarget evaluates e for the target path. A target with `[*]` wildcards is
+// expanded against data and e is evaluated once per element, with the wildcards of
+// the paths in e filled with the indexes of that element.
+func evalExprTarget(spec *Config, data []byte, target string, e *Expression, check func(target string, result interface{}) error) ([]exprResult, error) {
+	paths, err := expandWildcards(data, target, false, spec.KeySeparator)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]exprResult, len(paths))
+	for i, p := range paths {
+		ctx := &exprContext{data: data, require: spec.Require, keySeparator: spec.KeySeparator, indexes: p.indexes}
+		result, err := e.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if check != nil {
+			if err = check(target, result); err != nil {
+				return nil, err
+			}
+		}
+		value, err := encodeExprValue(result)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = exprResult{path: p.path, value: value}
+	}
+	return results, nil
+}
@@ -0,0 +1,65 @@
+package transform
+
+import "testing"
+
+func TestExpr(t *testing.T) {
+	jsonIn := `{"first":"ada","last":"Lovelace","age":36,"items":[{"sku":"a","qty":1},{"sku":"b","qty":5}]}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"strings and conditionals",
+			`{"name": "upper(substr(first, 0, 1)) + substr(first, 1) + ' ' + last", "group": "age >= 18 ? 'adult' : 'minor'"}`,
+			`{"first":"ada","last":"Lovelace","age":36,"items":[{"sku":"a","qty":1},{"sku":"b","qty":5}],"name":"Ada Lovelace","group":"adult"}`,
+		},
+		{
+			"booleans and values of any type",
+			`{"flags.senior": "age > 65", "flags.items": "items", "flags.none": "missing"}`,
+			`{"first":"ada","last":"Lovelace","age":36,"items":[{"sku":"a","qty":1},{"sku":"b","qty":5}],"flags":{"senior":false,"items":[{"sku":"a","qty":1},{"sku":"b","qty":5}],"none":null}}`,
+		},
+		{
+			"per element wildcard target",
+			`{"items[*].bulk": "items[*].qty > 1"}`,
+			`{"first":"ada","last":"Lovelace","age":36,"items":[{"sku":"a","qty":1,"bulk":false},{"sku":"b","qty":5,"bulk":true}]}`,
+		},
+		{
+			"targets are evaluated against the input",
+			`{"first": "last", "last": "first"}`,
+			`{"first":"Lovelace","last":"ada","age":36,"items":[{"sku":"a","qty":1},{"sku":"b","qty":5}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Expr, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(tc.jsonOut))
+			if !areEqual {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestExprInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"x": true}`,
+		`{"x": "a +"}`,
+		`{"x": "upper()"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareExpr(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid expr spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
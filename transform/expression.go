@@ -0,0 +1,1283 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Expression is a parsed expression over paths in a json document and literals, such
+// as `price * quantity` or `status == 'active' && len(items) > 0`. It is immutable
+// once parsed, so a single Expression may be evaluated concurrently.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// exprContext holds what an expression is evaluated against.
+type exprContext struct {
+	data         []byte
+	require      bool
+	keySeparator string
+	// indexes fill the wildcards of paths, see fillWildcards
+	indexes []int
+}
+
+// exprNode is a node of the expression tree. Evaluation yields one of nil, bool,
+// string, int64, float64, []interface{} or map[string]interface{}.
+type exprNode interface {
+	eval(ctx *exprContext) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+type pathNode struct {
+	path string
+	// quoted paths are used verbatim, bare paths are written with `.` separators
+	quoted bool
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+// logicalNode is a short-circuiting `&&` or `||`.
+type logicalNode struct {
+	op          string
+	left, right exprNode
+}
+
+// condNode is the conditional `cond ? then : otherwise`, also written `if(cond,
+// then, otherwise)`. Only the selected branch is evaluated.
+type condNode struct {
+	cond, then, otherwise exprNode
+}
+
+type callNode struct {
+	name string
+	fn   exprFunc
+	args []exprNode
+}
+
+// exprFunc implements a built-in function over already evaluated arguments.
+type exprFunc struct {
+	minArgs, maxArgs int // maxArgs < 0 means variadic
+	call             func(args []interface{}) (interface{}, error)
+}
+
+// ParseExpression parses the expression in source, returning a ParseError that
+// describes the first syntax error.
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := lexExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// Eval evaluates the expression against the json document data, whose paths are
+// separated by keySeparator. The result is one of nil, bool, string, int64, float64,
+// []interface{} or map[string]interface{}.
+func (e *Expression) Eval(data []byte, keySeparator string) (interface{}, error) {
+	return e.eval(&exprContext{data: data, keySeparator: keySeparator})
+}
+
+// EvalBool evaluates the expression against data and reports whether the result is
+// truthy. Null, false, zero, the empty string and empty arrays and objects are falsy;
+// every other value is truthy.
+func (e *Expression) EvalBool(data []byte, keySeparator string) (bool, error) {
+	result, err := e.Eval(data, keySeparator)
+	if err != nil {
+		return false, err
+	}
+	return truthy(result), nil
+}
+
+func (e *Expression) eval(ctx *exprContext) (interface{}, error) {
+	return e.root.eval(ctx)
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// lexing
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokPath
+	tokQuotedPath
+	tokIdent
+	tokOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// exprOperators lists operators longest first so that the lexer matches greedily.
+var exprOperators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ",",
+}
+
+func lexExpr(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// exponent
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				i++
+				if i < len(runes) && (runes[i] == '+' || runes[i] == '-') {
+					i++
+				}
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+		case r == '\'' || r == '"':
+			start := i
+			text, end, err := lexString(runes, i)
+			if err != nil {
+				return nil, ParseError(fmt.Sprintf("Warn: Invalid expression %q: %v at %d", source, err, start))
+			}
+			i = end
+			tokens = append(tokens, token{kind: tokString, text: text, pos: start})
+		case r == '`':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '`' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, ParseError(fmt.Sprintf("Warn: Invalid expression %q: unterminated path at %d", source, start))
+			}
+			tokens = append(tokens, token{kind: tokQuotedPath, text: string(runes[start+1 : i]), pos: start})
+			i++
+		case isIdentStart(r):
+			start := i
+			kind, end, err := lexPath(runes, i)
+			if err != nil {
+				return nil, ParseError(fmt.Sprintf("Warn: Invalid expression %q: %v at %d", source, err, start))
+			}
+			i = end
+			tokens = append(tokens, token{kind: kind, text: string(runes[start:i]), pos: start})
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if hasRunePrefix(runes[i:], op) {
+					tokens = append(tokens, token{kind: tokOperator, text: op, pos: i})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, ParseError(fmt.Sprintf("Warn: Invalid expression %q: unexpected character %q at %d", source, r, i))
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+func hasRunePrefix(runes []rune, prefix string) bool {
+	i := 0
+	for _, r := range prefix {
+		if i >= len(runes) || runes[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// lexPath scans an identifier starting at i, extended into a path by `.key` and
+// `[index]` suffixes. It returns tokPath if the identifier was extended.
+func lexPath(runes []rune, i int) (tokenKind, int, error) {
+	kind := tokIdent
+	for i < len(runes) {
+		switch {
+		case isIdentPart(runes[i]):
+			i++
+		case runes[i] == '.' && i+1 < len(runes) && isIdentStart(runes[i+1]):
+			kind = tokPath
+			i++
+		case runes[i] == '[':
+			kind = tokPath
+			for i < len(runes) && runes[i] != ']' {
+				i++
+			}
+			if i >= len(runes) {
+				return kind, i, fmt.Errorf("unterminated array index")
+			}
+			i++
+		default:
+			return kind, i, nil
+		}
+	}
+	return kind, i, nil
+}
+
+// lexString scans a string literal starting at the quote at i, returning its
+// unescaped text and the index after the closing quote. Either quote may be used and
+// the escapes are those of json strings, plus `\'`.
+func lexString(runes []rune, i int) (string, int, error) {
+	quote := runes[i]
+	var buffer strings.Builder
+	for i++; i < len(runes); i++ {
+		r := runes[i]
+		if r == quote {
+			return buffer.String(), i + 1, nil
+		}
+		if r != '\\' {
+			buffer.WriteRune(r)
+			continue
+		}
+		i++
+		if i >= len(runes) {
+			break
+		}
+		switch runes[i] {
+		case '"', '\'', '\\', '/':
+			buffer.WriteRune(runes[i])
+		case 'b':
+			buffer.WriteByte('\b')
+		case 'f':
+			buffer.WriteByte('\f')
+		case 'n':
+			buffer.WriteByte('\n')
+		case 'r':
+			buffer.WriteByte('\r')
+		case 't':
+			buffer.WriteByte('\t')
+		case 'u':
+			r, ok := lexUnicodeEscape(runes, i)
+			if !ok {
+				return "", i, fmt.Errorf("invalid unicode escape")
+			}
+			i += 4
+			// characters outside the basic multilingual plane are escaped as a
+			// surrogate pair, e.g. `\uD83D\uDE00`
+			if utf16.IsSurrogate(r) && i+2 < len(runes) && runes[i+1] == '\\' && runes[i+2] == 'u' {
+				if low, ok := lexUnicodeEscape(runes, i+2); ok {
+					if pair := utf16.DecodeRune(r, low); pair != unicode.ReplacementChar {
+						r = pair
+						i += 6
+					}
+				}
+			}
+			buffer.WriteRune(r)
+		default:
+			return "", i, fmt.Errorf("invalid escape %q", runes[i])
+		}
+	}
+	return "", i, fmt.Errorf("unterminated string")
+}
+
+// lexUnicodeEscape returns the code unit of the four hex digits following the `u` of a
+// `\u` escape at i.
+func lexUnicodeEscape(runes []rune, i int) (rune, bool) {
+	if i+4 >= len(runes) {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(code), true
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
+
+// parsing
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given operators.
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return ParseError(fmt.Sprintf("Warn: Invalid expression at %d: %s", p.peek().pos, fmt.Sprintf(format, args...)))
+}
+
+func (p *exprParser) parseExpression() (exprNode, error) {
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	then, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept(":"); !ok {
+		return nil, p.errorf("expected \":\"")
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &condNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: op, left: left, right: right}
+	}
+}
+
+// parseComparison parses equality and ordering operators, which share a precedence
+// level below the arithmetic operators.
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("==", "!=", "<", "<=", ">", ">=")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.accept("-", "+", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		value, err := parseNumber(t.text)
+		if err != nil {
+			return nil, ParseError(fmt.Sprintf("Warn: Invalid expression at %d: bad number %q", t.pos, t.text))
+		}
+		return &literalNode{value: value}, nil
+	case tokString:
+		return &literalNode{value: t.text}, nil
+	case tokQuotedPath:
+		return &pathNode{path: t.text, quoted: true}, nil
+	case tokPath:
+		return &pathNode{path: t.text}, nil
+	case tokIdent:
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(t)
+		}
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		return &pathNode{path: t.text}, nil
+	case tokOperator:
+		if t.text == "(" {
+			inner, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, p.errorf("expected \")\"")
+			}
+			return inner, nil
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, ParseError(fmt.Sprintf("Warn: Invalid expression at %d: unexpected end of expression", t.pos))
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Invalid expression at %d: unexpected %q", t.pos, t.text))
+}
+
+// parseCall parses the arguments of a function call whose name and opening
+// parenthesis have been consumed.
+func (p *exprParser) parseCall(name token) (exprNode, error) {
+	fn, ok := exprFuncs[name.text]
+	if !ok && name.text != "if" {
+		return nil, ParseError(fmt.Sprintf("Warn: Invalid expression at %d: unknown function %q", name.pos, name.text))
+	}
+	var args []exprNode
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(")"); ok {
+				break
+			}
+			if _, ok := p.accept(","); !ok {
+				return nil, p.errorf("expected \",\" or \")\"")
+			}
+		}
+	}
+	// if is evaluated lazily, so it is a conditional rather than a function
+	if name.text == "if" {
+		if len(args) != 3 {
+			return nil, ParseError(fmt.Sprintf("Warn: Invalid expression at %d: wrong number of arguments to if", name.pos))
+		}
+		return &condNode{cond: args[0], then: args[1], otherwise: args[2]}, nil
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, ParseError(fmt.Sprintf("Warn: Invalid expression at %d: wrong number of arguments to %s", name.pos, name.text))
+	}
+	return &callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+// evaluation
+
+func (n *literalNode) eval(ctx *exprContext) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *pathNode) eval(ctx *exprContext) (interface{}, error) {
+	path := n.path
+	if !n.quoted && ctx.keySeparator != "." {
+		path = strings.Replace(path, ".", ctx.keySeparator, -1)
+	}
+	raw := ctx.data
+	if path != "$" {
+		var err error
+		raw, err = getJSONRaw(ctx.data, fillWildcards(path, ctx.indexes), ctx.require, ctx.keySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(decoded), nil
+}
+
+func (n *unaryNode) eval(ctx *exprContext) (interface{}, error) {
+	operand, err := n.operand.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !truthy(operand), nil
+	}
+	if operand == nil {
+		return nil, nil
+	}
+	switch n.op {
+	case "-":
+		return arithmetic("-", int64(0), operand)
+	default:
+		if !isNumber(operand) {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to apply unary + to %v", operand))
+		}
+		return operand, nil
+	}
+}
+
+func (n *binaryNode) eval(ctx *exprContext) (interface{}, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		return exprOrder(n.op, left, right)
+	case "+":
+		// a string operand makes + a concatenation
+		_, leftStr := left.(string)
+		_, rightStr := right.(string)
+		if (leftStr || rightStr) && left != nil && right != nil {
+			return exprString(left) + exprString(right), nil
+		}
+	}
+	return arithmetic(n.op, left, right)
+}
+
+func (n *logicalNode) eval(ctx *exprContext) (interface{}, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(left) == (n.op == "||") {
+		return truthy(left), nil
+	}
+	right, err := n.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+func (n *condNode) eval(ctx *exprContext) (interface{}, error) {
+	cond, err := n.cond.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(cond) {
+		return n.then.eval(ctx)
+	}
+	return n.otherwise.eval(ctx)
+}
+
+func (n *callNode) eval(ctx *exprContext) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return n.fn.call(args)
+}
+
+// values
+
+// parseNumber parses a json number, keeping integers as int64 when they fit.
+func parseNumber(text string) (interface{}, error) {
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i, nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// normalizeNumbers replaces the json.Number values produced by decodeJSON with
+// int64 or float64 values.
+func normalizeNumbers(v interface{}) interface{} {
+	switch vTyped := v.(type) {
+	case json.Number:
+		n, err := parseNumber(vTyped.String())
+		if err != nil {
+			// out of range for float64, keep the closest value
+			f, _ := vTyped.Float64()
+			return f
+		}
+		return n
+	case []interface{}:
+		for i, item := range vTyped {
+			vTyped[i] = normalizeNumbers(item)
+		}
+	case map[string]interface{}:
+		for k, item := range vTyped {
+			vTyped[k] = normalizeNumbers(item)
+		}
+	}
+	return v
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v interface{}) float64 {
+	switch vTyped := v.(type) {
+	case int64:
+		return float64(vTyped)
+	case float64:
+		return vTyped
+	}
+	return math.NaN()
+}
+
+// truthy reports whether v counts as true in a condition: null, false, zero, the
+// empty string and empty arrays and objects do not.
+func truthy(v interface{}) bool {
+	switch vTyped := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return vTyped
+	case string:
+		return vTyped != ""
+	case int64:
+		return vTyped != 0
+	case float64:
+		return vTyped != 0
+	case []interface{}:
+		return len(vTyped) > 0
+	case map[string]interface{}:
+		return len(vTyped) > 0
+	}
+	return true
+}
+
+// exprEqual compares two values deeply, comparing numbers by value so that 1 equals 1.0.
+func exprEqual(a, b interface{}) bool {
+	if isNumber(a) && isNumber(b) {
+		return compareNumbers(a, b) == 0
+	}
+	switch aTyped := a.(type) {
+	case []interface{}:
+		bTyped, ok := b.([]interface{})
+		if !ok || len(aTyped) != len(bTyped) {
+			return false
+		}
+		for i := range aTyped {
+			if !exprEqual(aTyped[i], bTyped[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bTyped, ok := b.(map[string]interface{})
+		if !ok || len(aTyped) != len(bTyped) {
+			return false
+		}
+		for k, item := range aTyped {
+			other, ok := bTyped[k]
+			if !ok || !exprEqual(item, other) {
+				return false
+			}
+		}
+		return true
+	case nil, bool, string:
+		return a == b
+	}
+	return false
+}
+
+// exprOrder applies an ordering operator to two numbers or two strings. Ordering
+// against null is false; any other mix of types is an error.
+func exprOrder(op string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return false, nil
+	}
+	var cmp int
+	leftStr, leftOk := left.(string)
+	rightStr, rightOk := right.(string)
+	switch {
+	case leftOk && rightOk:
+		cmp = strings.Compare(leftStr, rightStr)
+	case isNumber(left) && isNumber(right):
+		cmp = compareNumbers(left, right)
+	default:
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to compare %v %s %v", left, op, right))
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	}
+	return cmp >= 0, nil
+}
+
+// exprString converts a value to its string form: strings are used as-is and any
+// other value as its json encoding.
+func exprString(v interface{}) string {
+	switch vTyped := v.(type) {
+	case string:
+		return vTyped
+	case float64:
+		return strconv.FormatFloat(vTyped, 'f', -1, 64)
+	}
+	encoded, err := encodeExprValue(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// arithmetic applies a numeric operator. Integer operands stay integers, except
+// for inexact division, and integer overflow is an error rather than a silent
+// conversion to float. A null operand yields null.
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if !isNumber(left) || !isNumber(right) {
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to apply %s to %v and %v", op, left, right))
+	}
+	a, aInt := left.(int64)
+	b, bInt := right.(int64)
+	if aInt && bInt {
+		return intArithmetic(op, a, b)
+	}
+	x, y := toFloat(left), toFloat(right)
+	var result float64
+	switch op {
+	case "+":
+		result = x + y
+	case "-":
+		result = x - y
+	case "*":
+		result = x * y
+	case "/":
+		if y == 0 {
+			return nil, ParseError("Warn: Division by zero")
+		}
+		result = x / y
+	case "%":
+		if y == 0 {
+			return nil, ParseError("Warn: Division by zero")
+		}
+		result = math.Mod(x, y)
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, ParseError(fmt.Sprintf("Warn: Result of %v %s %v is out of range", left, op, right))
+	}
+	return result, nil
+}
+
+func intArithmetic(op string, a, b int64) (interface{}, error) {
+	overflow := ParseError(fmt.Sprintf("Warn: Integer overflow computing %d %s %d", a, op, b))
+	switch op {
+	case "+":
+		c := a + b
+		if (c > a) != (b > 0) {
+			return nil, overflow
+		}
+		return c, nil
+	case "-":
+		c := a - b
+		if (c < a) != (b > 0) {
+			return nil, overflow
+		}
+		return c, nil
+	case "*":
+		if a == 0 || b == 0 {
+			return int64(0), nil
+		}
+		c := a * b
+		if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+			return nil, overflow
+		}
+		return c, nil
+	case "/", "%":
+		if b == 0 {
+			return nil, ParseError("Warn: Division by zero")
+		}
+		if b == -1 && a == math.MinInt64 {
+			return nil, overflow
+		}
+		if op == "%" {
+			return a % b, nil
+		}
+		if a%b == 0 {
+			return a / b, nil
+		}
+		return float64(a) / float64(b), nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Unknown operator %s", op))
+}
+
+// encodeExprValue marshals the result of an expression to raw json.
+func encodeExprValue(v interface{}) ([]byte, error) {
+	switch vTyped := v.(type) {
+	case int64:
+		return []byte(strconv.FormatInt(vTyped, 10)), nil
+	case float64:
+		if math.IsInf(vTyped, 0) || math.IsNaN(vTyped) {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to encode %v as json", vTyped))
+		}
+	}
+	return encodeJSON(v)
+}
+
+// built-in functions
+
+var exprFuncs map[string]exprFunc
+
+func init() {
+	exprFuncs = map[string]exprFunc{
+		"abs":   {1, 1, exprAbs},
+		"round": {1, 2, exprRound},
+		"floor": {1, 1, func(args []interface{}) (interface{}, error) { return roundWith(math.Floor, args[0]) }},
+		"ceil":  {1, 1, func(args []interface{}) (interface{}, error) { return roundWith(math.Ceil, args[0]) }},
+		"min":   {1, -1, func(args []interface{}) (interface{}, error) { return exprExtreme(args, -1) }},
+		"max":   {1, -1, func(args []interface{}) (interface{}, error) { return exprExtreme(args, 1) }},
+		"sum":   {1, -1, exprSum},
+		"avg":   {1, -1, exprAvg},
+
+		"upper":      {1, 1, stringFunc(strings.ToUpper)},
+		"lower":      {1, 1, stringFunc(strings.ToLower)},
+		"trim":       {1, 1, stringFunc(strings.TrimSpace)},
+		"startsWith": {2, 2, stringPredicate(strings.HasPrefix)},
+		"endsWith":   {2, 2, stringPredicate(strings.HasSuffix)},
+		"contains":   {2, 2, exprContains},
+		"len":        {1, 1, exprLen},
+		"substr":     {2, 3, exprSubstr},
+		"concat":     {1, -1, exprConcat},
+		"string":     {1, 1, exprToString},
+		"number":     {1, 1, exprToNumber},
+	}
+}
+
+// numericArgs flattens array arguments and returns the numbers among them. Nulls
+// are skipped; any other value is an error.
+func numericArgs(args []interface{}) ([]interface{}, error) {
+	var numbers []interface{}
+	for _, arg := range args {
+		switch argTyped := arg.(type) {
+		case nil:
+		case []interface{}:
+			flattened, err := numericArgs(argTyped)
+			if err != nil {
+				return nil, err
+			}
+			numbers = append(numbers, flattened...)
+		case int64, float64:
+			numbers = append(numbers, arg)
+		default:
+			return nil, ParseError(fmt.Sprintf("Warn: Expected a number, got %v", arg))
+		}
+	}
+	return numbers, nil
+}
+
+func exprAbs(args []interface{}) (interface{}, error) {
+	switch argTyped := args[0].(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		if argTyped < 0 {
+			return intArithmetic("-", 0, argTyped)
+		}
+		return argTyped, nil
+	case float64:
+		return math.Abs(argTyped), nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Expected a number, got %v", args[0]))
+}
+
+// exprRound rounds half away from zero to the given number of decimal places,
+// zero by default. Rounding to whole units yields an integer when it fits.
+func exprRound(args []interface{}) (interface{}, error) {
+	places := int64(0)
+	if len(args) > 1 {
+		var ok bool
+		if places, ok = args[1].(int64); !ok {
+			return nil, ParseError(fmt.Sprintf("Warn: Expected an integer number of places, got %v", args[1]))
+		}
+	}
+	if places <= 0 {
+		if places == 0 {
+			return roundWith(math.Round, args[0])
+		}
+		return roundWith(func(f float64) float64 {
+			scale := math.Pow10(int(-places))
+			return math.Round(f/scale) * scale
+		}, args[0])
+	}
+	switch argTyped := args[0].(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return argTyped, nil
+	case float64:
+		scale := math.Pow10(int(places))
+		rounded := math.Round(argTyped*scale) / scale
+		if math.IsInf(rounded, 0) || math.IsNaN(rounded) {
+			// too many places to scale, the value is already as precise as it gets
+			return argTyped, nil
+		}
+		return rounded, nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Expected a number, got %v", args[0]))
+}
+
+// roundWith applies a float rounding function, returning an integer when it fits.
+func roundWith(round func(float64) float64, v interface{}) (interface{}, error) {
+	switch vTyped := v.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		rounded := round(float64(vTyped))
+		// whole numbers beyond float precision are already rounded
+		if math.Abs(float64(vTyped)) < 1<<53 {
+			return int64(rounded), nil
+		}
+		return vTyped, nil
+	case float64:
+		rounded := round(vTyped)
+		if rounded >= math.MinInt64 && rounded < math.MaxInt64 {
+			return int64(rounded), nil
+		}
+		return rounded, nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Expected a number, got %v", v))
+}
+
+// exprExtreme returns the smallest (sign < 0) or largest (sign > 0) number.
+func exprExtreme(args []interface{}, sign int) (interface{}, error) {
+	numbers, err := numericArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	var best interface{}
+	for _, n := range numbers {
+		if best == nil || compareNumbers(n, best) == sign {
+			best = n
+		}
+	}
+	return best, nil
+}
+
+// compareNumbers returns -1, 0 or 1, comparing integers exactly.
+func compareNumbers(a, b interface{}) int {
+	aInt, aOk := a.(int64)
+	bInt, bOk := b.(int64)
+	if aOk && bOk {
+		switch {
+		case aInt < bInt:
+			return -1
+		case aInt > bInt:
+			return 1
+		}
+		return 0
+	}
+	x, y := toFloat(a), toFloat(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func exprSum(args []interface{}) (interface{}, error) {
+	numbers, err := numericArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	var total interface{} = int64(0)
+	for _, n := range numbers {
+		if total, err = arithmetic("+", total, n); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+func exprAvg(args []interface{}) (interface{}, error) {
+	numbers, err := numericArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	total, err := exprSum(numbers)
+	if err != nil {
+		return nil, err
+	}
+	return arithmetic("/", total, int64(len(numbers)))
+}
+
+// stringFunc adapts a string conversion to a function of one string argument. A null
+// argument yields null.
+func stringFunc(convert func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, ParseError(fmt.Sprintf("Warn: Expected a string, got %v", args[0]))
+		}
+		return convert(s), nil
+	}
+}
+
+// stringPredicate adapts a test of a string against another to a function of two
+// string arguments. A null first argument yields null.
+func stringPredicate(test func(s, other string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, sOk := args[0].(string)
+		other, otherOk := args[1].(string)
+		if !sOk || !otherOk {
+			return nil, ParseError(fmt.Sprintf("Warn: Expected strings, got %v and %v", args[0], args[1]))
+		}
+		return test(s, other), nil
+	}
+}
+
+// exprContains reports whether a string contains a substring or an array contains
+// an element.
+func exprContains(args []interface{}) (interface{}, error) {
+	switch container := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return stringPredicate(strings.Contains)(args)
+	case []interface{}:
+		for _, item := range container {
+			if exprEqual(item, args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Expected a string or array, got %v", args[0]))
+}
+
+// exprLen returns the number of characters of a string, elements of an array or
+// keys of an object.
+func exprLen(args []interface{}) (interface{}, error) {
+	switch vTyped := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return int64(utf8.RuneCountInString(vTyped)), nil
+	case []interface{}:
+		return int64(len(vTyped)), nil
+	case map[string]interface{}:
+		return int64(len(vTyped)), nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Expected a string, array or object, got %v", args[0]))
+}
+
+// exprSubstr returns the characters of a string from start, counted from the end
+// when negative, up to an optional length. Out of range bounds are clamped.
+func exprSubstr(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, ParseError(fmt.Sprintf("Warn: Expected a string, got %v", args[0]))
+	}
+	runes := []rune(s)
+	start, ok := args[1].(int64)
+	if !ok {
+		return nil, ParseError(fmt.Sprintf("Warn: Expected an integer start, got %v", args[1]))
+	}
+	if start < 0 {
+		start += int64(len(runes))
+	}
+	start = clampIndex(start, len(runes))
+	end := int64(len(runes))
+	if len(args) > 2 {
+		length, ok := args[2].(int64)
+		if !ok || length < 0 {
+			return nil, ParseError(fmt.Sprintf("Warn: Expected a non-negative integer length, got %v", args[2]))
+		}
+		if length < end-start {
+			end = start + length
+		}
+	}
+	return string(runes[start:end]), nil
+}
+
+func clampIndex(i int64, length int) int64 {
+	if i < 0 {
+		return 0
+	}
+	if i > int64(length) {
+		return int64(length)
+	}
+	return i
+}
+
+// exprConcat joins the string forms of its arguments, treating null as empty.
+func exprConcat(args []interface{}) (interface{}, error) {
+	var buffer strings.Builder
+	for _, arg := range args {
+		if arg != nil {
+			buffer.WriteString(exprString(arg))
+		}
+	}
+	return buffer.String(), nil
+}
+
+func exprToString(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	return exprString(args[0]), nil
+}
+
+// exprToNumber converts strings and booleans to numbers. A string that is not a
+// number yields null.
+func exprToNumber(args []interface{}) (interface{}, error) {
+	switch vTyped := args[0].(type) {
+	case nil, int64, float64:
+		return vTyped, nil
+	case bool:
+		if vTyped {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		// only json numbers are accepted, not Go syntax such as "Inf" or "0x1p4"
+		trimmed := []byte(strings.TrimSpace(vTyped))
+		if !json.Valid(trimmed) {
+			return nil, nil
+		}
+		decoded, err := decodeJSON(trimmed)
+		if n, ok := decoded.(json.Number); ok && err == nil {
+			return normalizeNumbers(n), nil
+		}
+		return nil, nil
+	}
+	return nil, ParseError(fmt.Sprintf("Warn: Unable to convert %v to a number", args[0]))
+}
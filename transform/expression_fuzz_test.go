@@ -0,0 +1,39 @@
+//go:build go1.18
+// +build go1.18
+
+package transform
+
+import "testing"
+
+func FuzzParseExpression(f *testing.F) {
+	seeds := []string{
+		"price * quantity",
+		"round(sum(items[*].price) / len(items), 2)",
+		"status == 'active' && !deleted || `odd-key` > 3",
+		`age >= 18 ? upper(name) : concat("minor: ", name)`,
+		`substr('héllo', -3, 2) + string(1.5e3)`,
+		"if(a.b[0], number(c), null)",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	data := []byte(`{"price":1.5,"quantity":2,"items":[{"price":1}],"status":"active","odd-key":4,"age":20,"name":"x","a":{"b":[true]},"c":"7"}`)
+
+	f.Fuzz(func(t *testing.T, source string) {
+		e, err := ParseExpression(source)
+		if err != nil {
+			if _, ok := err.(ParseError); !ok {
+				t.Fatalf("got %T; want a ParseError", err)
+			}
+			return
+		}
+		if e.String() != source {
+			t.Fatalf("got source %q; want %q", e.String(), source)
+		}
+		// evaluation may fail, but must not panic
+		result, err := e.Eval(data, ".")
+		if err == nil {
+			encodeExprValue(result)
+		}
+	})
+}
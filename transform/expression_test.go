@@ -0,0 +1,156 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpressionEval(t *testing.T) {
+	jsonIn := `{"name":" Ada Lovelace ","age":36,"score":4.5,"active":true,"tags":["math","poet"],"address":{"city":"London"},"nothing":null,"id":9007199254740993}`
+	testCases := []struct {
+		source string
+		want   interface{}
+	}{
+		{`"a" + 'b'`, "ab"},
+		{`'it\'s' + " \"quoted\"\n"`, "it's \"quoted\"\n"},
+		{`'é'`, "é"},
+		{`'\u00e9\uD83D\uDE00'`, "é😀"},
+		{`len('\uD83D\uDE00')`, int64(1)},
+		{`'\uD83D' == '\uFFFD'`, true},
+		{`trim(name)`, "Ada Lovelace"},
+		{`upper(trim(name))`, "ADA LOVELACE"},
+		{`lower(address.city)`, "london"},
+		{`'age: ' + age`, "age: 36"},
+		{`score + ' points'`, "4.5 points"},
+		{`len(trim(name))`, int64(12)},
+		{`len(tags) + len(address)`, int64(3)},
+		{`substr(trim(name), 4)`, "Lovelace"},
+		{`substr(trim(name), 0, 3)`, "Ada"},
+		{`substr(trim(name), -4, 10)`, "lace"},
+		{`substr('héllo', 1, 2)`, "él"},
+		{`concat(address.city, '-', missing, '-', age)`, "London--36"},
+		{`string(age)`, "36"},
+		{`string(tags)`, `["math","poet"]`},
+		{`number('42') + number(' 1.5 ')`, 43.5},
+		{`number('abc')`, nil},
+		{`number('Inf')`, nil},
+		{`number(active)`, int64(1)},
+		{`id + 0`, int64(9007199254740993)},
+		{`age == 36`, true},
+		{`age == 36.0`, true},
+		{`age != 36`, false},
+		{`name == ' Ada Lovelace '`, true},
+		{`address.city >= 'London' && address.city < 'M'`, true},
+		{`age > 18 && score <= 4`, false},
+		{`missing > 1`, false},
+		{`missing == null`, true},
+		{`nothing == null && !nothing`, true},
+		{`!active || age > 40`, false},
+		{`false || tags`, true},
+		{`contains(tags, 'poet')`, true},
+		{`contains(name, 'Love') && startsWith(trim(name), 'Ada') && endsWith(name, ' ')`, true},
+		{`contains(missing, 'a')`, nil},
+		{`age >= 18 ? 'adult' : 'minor'`, "adult"},
+		{`age < 18 ? 'minor' : age < 65 ? 'adult' : 'senior'`, "adult"},
+		{`if(active, 1, 1 / 0)`, int64(1)},
+		{`false && 1 / 0`, false},
+		{`true`, true},
+		{`null`, nil},
+		{`len($)`, int64(8)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			e, err := ParseExpression(tc.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := e.Eval([]byte(jsonIn), ".")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %#v; want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpressionEvalBool(t *testing.T) {
+	testCases := []struct {
+		source string
+		want   bool
+	}{
+		{"zero", false},
+		{"empty", false},
+		{"list", false},
+		{"object", false},
+		{"missing", false},
+		{"text", true},
+		{"count", true},
+		{"!empty && text == 'x'", true},
+	}
+
+	data := []byte(`{"zero":0,"empty":"","list":[],"object":{},"text":"x","count":0.5}`)
+	for _, tc := range testCases {
+		e, err := ParseExpression(tc.source)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := e.EvalBool(data, ".")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v; want %v", tc.source, got, tc.want)
+		}
+	}
+}
+
+func TestExpressionEvalErrors(t *testing.T) {
+	testCases := []string{
+		"name < 1",
+		"name - 1",
+		"-name",
+		"upper(age)",
+		"startsWith(name, 1)",
+		"len(age)",
+		"substr(name, 'a')",
+		"substr(name, 0, -1)",
+		"contains(age, 1)",
+	}
+
+	for _, source := range testCases {
+		e, err := ParseExpression(source)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %v", source, err)
+		}
+		if _, err = e.Eval([]byte(`{"name":"a","age":1}`), "."); err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Expression: ", source)
+		}
+	}
+}
+
+func TestExpressionInvalid(t *testing.T) {
+	testCases := []string{
+		"'unterminated",
+		`"bad \q escape"`,
+		`'\u12'`,
+		"a ? b",
+		"a ? b :",
+		"a == == b",
+		"a & b",
+		"a | b",
+		"if(a, b)",
+		"!",
+		"a = 1",
+	}
+
+	for _, source := range testCases {
+		if _, err := ParseExpression(source); err == nil {
+			t.Error("Should have thrown a ParseError for an invalid expression.")
+			t.Log("Expression: ", source)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// filterSpec describes the array to filter and the predicate its elements must match.
+type filterSpec struct {
+	path       string
+	targetPath string
+	where      filterPredicate
+}
+
+// filterPredicate is a condition on a single array element.
+type filterPredicate interface {
+	match(element []byte, keySeparator string) (bool, error)
+}
+
+// allPredicate matches when every predicate matches.
+type allPredicate []filterPredicate
+
+// anyPredicate matches when at least one predicate matches.
+type anyPredicate []filterPredicate
+
+// notPredicate matches when its predicate does not.
+type notPredicate struct {
+	predicate filterPredicate
+}
+
+// valuePredicate applies a set of checks to the value at a path of the element.
+type valuePredicate struct {
+	path   string
+	checks []func(value interface{}) bool
+}
+
+// exprPredicate evaluates an expression against the element.
+type exprPredicate struct {
+	e *Expression
+}
+
+// Filter removes the elements of an array that do not match a predicate, writing the
+// result in place or to `targetPath`. The predicate is parsed once per spec, see
+// PrepareFilter.
+func Filter(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+	f := parsed.(*filterSpec)
+	return transformArrays(spec, data, f.path, f.targetPath, func(array []byte) ([]byte, error) {
+		return f.filter(array, spec.KeySeparator)
+	})
+}
+
+// PrepareFilter parses the filter spec, compiling its predicate, ahead of
+// transforming any data.
+func PrepareFilter(spec *Config) error {
+	return spec.prepareWith(parseFilterSpec)
+}
+
+func parseFilterSpec(spec *Config) (interface{}, error) {
+	f := &filterSpec{}
+	var ok bool
+	if f.path, ok = (*spec.Spec)["path"].(string); !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"path\"")
+	}
+	if targetPath, ok := (*spec.Spec)["targetPath"]; ok {
+		if f.targetPath, ok = targetPath.(string); !ok {
+			return nil, SpecError("Warn: Invalid spec. \"targetPath\" must be a string")
+		}
+	}
+	where, ok := (*spec.Spec)["where"]
+	if !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"where\"")
+	}
+	var err error
+	if f.where, err = parseFilterPredicate(where); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseFilterPredicate builds a predicate from its spec: `and`, `or` or `not` of
+// other predicates, an `expr`, or conditions on the value at a `path`.
+func parseFilterPredicate(v interface{}) (filterPredicate, error) {
+	predicateMap, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Predicate must be an object: %v", v))
+	}
+	for _, combinator := range []string{"and", "or", "not", "expr"} {
+		operand, ok := predicateMap[combinator]
+		if !ok {
+			continue
+		}
+		if len(predicateMap) != 1 {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be the only key of its predicate", combinator))
+		}
+		switch combinator {
+		case "and", "or":
+			operandList, ok := operand.([]interface{})
+			if !ok || len(operandList) == 0 {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a non-empty list of predicates", combinator))
+			}
+			predicates := make([]filterPredicate, len(operandList))
+			for i, item := range operandList {
+				predicate, err := parseFilterPredicate(item)
+				if err != nil {
+					return nil, err
+				}
+				predicates[i] = predicate
+			}
+			if combinator == "and" {
+				return allPredicate(predicates), nil
+			}
+			return anyPredicate(predicates), nil
+		case "not":
+			predicate, err := parseFilterPredicate(operand)
+			if err != nil {
+				return nil, err
+			}
+			return &notPredicate{predicate: predicate}, nil
+		default:
+			source, ok := operand.(string)
+			if !ok {
+				return nil, SpecError("Warn: Invalid spec. \"expr\" must be a string")
+			}
+			e, err := ParseExpression(source)
+			if err != nil {
+				return nil, SpecError(err.Error())
+			}
+			return &exprPredicate{e: e}, nil
+		}
+	}
+	return parseValuePredicate(predicateMap)
+}
+
+func parseValuePredicate(predicateMap map[string]interface{}) (filterPredicate, error) {
+	p := &valuePredicate{path: "$"}
+	for k, operand := range predicateMap {
+		operand := operand
+		switch k {
+		case "path":
+			path, ok := operand.(string)
+			if !ok {
+				return nil, SpecError("Warn: Invalid spec. Predicate \"path\" must be a string")
+			}
+			p.path = path
+		case "equals":
+			p.checks = append(p.checks, func(value interface{}) bool {
+				return exprEqual(value, operand)
+			})
+		case "in":
+			options, ok := operand.([]interface{})
+			if !ok {
+				return nil, SpecError("Warn: Invalid spec. \"in\" must be a list")
+			}
+			p.checks = append(p.checks, func(value interface{}) bool {
+				for _, option := range options {
+					if exprEqual(value, option) {
+						return true
+					}
+				}
+				return false
+			})
+		case "exists":
+			exists, ok := operand.(bool)
+			if !ok {
+				return nil, SpecError("Warn: Invalid spec. \"exists\" must be a boolean")
+			}
+			p.checks = append(p.checks, func(value interface{}) bool {
+				return (value != nil) == exists
+			})
+		case "regex":
+			pattern, ok := operand.(string)
+			if !ok {
+				return nil, SpecError("Warn: Invalid spec. \"regex\" must be a string")
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to compile regex %s: %v", pattern, err))
+			}
+			p.checks = append(p.checks, func(value interface{}) bool {
+				valueStr, ok := value.(string)
+				return ok && re.MatchString(valueStr)
+			})
+		case "gt", "gte", "lt", "lte":
+			_, isString := operand.(string)
+			if !isNumber(operand) && !isString {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a number or a string", k))
+			}
+			op := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[k]
+			p.checks = append(p.checks, func(value interface{}) bool {
+				// values of another type than the operand do not match
+				result, err := exprOrder(op, value, operand)
+				matched, _ := result.(bool)
+				return err == nil && matched
+			})
+		default:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown predicate key %q", k))
+		}
+	}
+	if len(p.checks) == 0 {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Predicate has no condition: %v", predicateMap))
+	}
+	return p, nil
+}
+
+// filter returns the raw array with only the elements matching the predicate.
+func (f *filterSpec) filter(array []byte, keySeparator string) ([]byte, error) {
+	elements, err := arrayElements(array)
+	if err != nil {
+		return nil, err
+	}
+	var kept [][]byte
+	for _, element := range elements {
+		matched, err := f.where.match(element, keySeparator)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			kept = append(kept, element)
+		}
+	}
+	return joinArray(kept), nil
+}
+
+func (p allPredicate) match(element []byte, keySeparator string) (bool, error) {
+	for _, predicate := range p {
+		matched, err := predicate.match(element, keySeparator)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p anyPredicate) match(element []byte, keySeparator string) (bool, error) {
+	for _, predicate := range p {
+		matched, err := predicate.match(element, keySeparator)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+func (p *notPredicate) match(element []byte, keySeparator string) (bool, error) {
+	matched, err := p.predicate.match(element, keySeparator)
+	return !matched, err
+}
+
+func (p *valuePredicate) match(element []byte, keySeparator string) (bool, error) {
+	raw := element
+	if p.path != "$" {
+		var err error
+		if raw, err = getJSONRaw(element, p.path, false, keySeparator); err != nil {
+			return false, err
+		}
+	}
+	value, err := decodeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+	value = normalizeNumbers(value)
+	for _, check := range p.checks {
+		if !check(value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (p *exprPredicate) match(element []byte, keySeparator string) (bool, error) {
+	return p.e.EvalBool(element, keySeparator)
+}
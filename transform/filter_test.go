@@ -0,0 +1,146 @@
+package transform
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	jsonIn := `{"items":[{"sku":"a","qty":0,"status":"active"},{"sku":"b","qty":5,"status":"inactive"},{"sku":"c","qty":2,"status":"active","gift":true}],"tags":["x1","y","x2"]}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"equals in place",
+			`{"path": "items", "where": {"path": "status", "equals": "active"}}`,
+			`{"items":[{"sku":"a","qty":0,"status":"active"},{"sku":"c","qty":2,"status":"active","gift":true}],"tags":["x1","y","x2"]}`,
+		},
+		{
+			"and with numeric compare to target path",
+			`{"path": "items", "targetPath": "active", "where": {"and": [{"path": "status", "equals": "active"}, {"path": "qty", "gt": 0}]}}`,
+			`{"items":[{"sku":"a","qty":0,"status":"active"},{"sku":"b","qty":5,"status":"inactive"},{"sku":"c","qty":2,"status":"active","gift":true}],"tags":["x1","y","x2"],"active":[{"sku":"c","qty":2,"status":"active","gift":true}]}`,
+		},
+		{
+			"or, not, in and exists",
+			`{"path": "items", "where": {"or": [{"path": "gift", "exists": true}, {"not": {"path": "sku", "in": ["a", "c"]}}]}}`,
+			`{"items":[{"sku":"b","qty":5,"status":"inactive"},{"sku":"c","qty":2,"status":"active","gift":true}],"tags":["x1","y","x2"]}`,
+		},
+		{
+			"range on one path",
+			`{"path": "items", "where": {"path": "qty", "gte": 1, "lt": 5}}`,
+			`{"items":[{"sku":"c","qty":2,"status":"active","gift":true}],"tags":["x1","y","x2"]}`,
+		},
+		{
+			"regex on scalar elements",
+			`{"path": "tags", "where": {"regex": "^x"}}`,
+			`{"items":[{"sku":"a","qty":0,"status":"active"},{"sku":"b","qty":5,"status":"inactive"},{"sku":"c","qty":2,"status":"active","gift":true}],"tags":["x1","x2"]}`,
+		},
+		{
+			"expression",
+			`{"path": "items", "where": {"expr": "qty * 2 > 5 || sku == 'a'"}}`,
+			`{"items":[{"sku":"a","qty":0,"status":"active"},{"sku":"b","qty":5,"status":"inactive"}],"tags":["x1","y","x2"]}`,
+		},
+		{
+			"no match yields an empty array",
+			`{"path": "items", "where": {"path": "qty", "equals": 100}}`,
+			`{"items":[],"tags":["x1","y","x2"]}`,
+		},
+		{
+			"missing path is skipped",
+			`{"path": "missing", "where": {"exists": true}}`,
+			jsonIn,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Filter, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(tc.jsonOut))
+			if !areEqual {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestFilterNestedWildcards(t *testing.T) {
+	spec := `{"path": "orders[*].addresses", "targetPath": "orders[*].billing", "where": {"path": "type", "in": ["billing", "both"]}}`
+	jsonIn := `{"orders":[{"addresses":[{"type":"billing","zip":"1"},{"type":"shipping","zip":"2"}]},{"addresses":[{"type":"both","zip":"3"}]}]}`
+	jsonOut := `{"orders":[{"addresses":[{"type":"billing","zip":"1"},{"type":"shipping","zip":"2"}],"billing":[{"type":"billing","zip":"1"}]},{"addresses":[{"type":"both","zip":"3"}],"billing":[{"type":"both","zip":"3"}]}]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Filter, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestFilterRootArray(t *testing.T) {
+	spec := `{"path": "$", "where": {"path": "id", "lte": 2}}`
+	jsonIn := `[{"id":1},{"id":3},{"id":2.5},{"id":"2"}]`
+	jsonOut := `[{"id":1}]`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Filter, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestFilterRequire(t *testing.T) {
+	testCases := []string{
+		`{"path": "missing", "where": {"exists": true}}`,
+		`{"path": "name", "where": {"exists": true}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, true)
+		_, err := getTransformTestWrapper(Filter, cfg, `{"name":"a"}`)
+		if err == nil {
+			t.Error("Should have thrown an error with require.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestFilterInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"where": {"exists": true}}`,
+		`{"path": "a"}`,
+		`{"path": "a", "targetPath": 1, "where": {"exists": true}}`,
+		`{"path": "a", "where": "x"}`,
+		`{"path": "a", "where": {"path": "x"}}`,
+		`{"path": "a", "where": {"path": "x", "equal": 1}}`,
+		`{"path": "a", "where": {"and": []}}`,
+		`{"path": "a", "where": {"or": [{"exists": "yes"}]}}`,
+		`{"path": "a", "where": {"not": {"regex": "("}}}`,
+		`{"path": "a", "where": {"in": "x"}}`,
+		`{"path": "a", "where": {"gt": true}}`,
+		`{"path": "a", "where": {"expr": "a +"}}`,
+		`{"path": "a", "where": {"expr": "a", "path": "b"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareFilter(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid filter spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// array index styles of flattened keys
+const (
+	arraysDot     = "dot"
+	arraysBracket = "bracket"
+	arraysNone    = "none"
+)
+
+// flattenSpec describes how keys are flattened or unflattened.
+type flattenSpec struct {
+	path       string
+	targetPath string
+	separator  string
+	arrays     string
+	depth      int
+}
+
+// Flatten turns a nested object into a single-level object whose keys are the paths
+// of the nested values, e.g. `{"user": {"name": "x"}}` becomes `{"user.name": "x"}`.
+func Flatten(spec *Config, data []byte) ([]byte, error) {
+	// the whole message is flattened by default, without any spec
+	spec = spec.orEmpty()
+	parsed, err := spec.parsedSpec(parseFlattenSpec)
+	if err != nil {
+		return nil, err
+	}
+	f := parsed.(*flattenSpec)
+	return transformObjects(spec, data, f.path, f.targetPath, func(object []byte) ([]byte, error) {
+		var fields []rawField
+		if err := f.flatten(object, "", 0, &fields); err != nil {
+			return nil, err
+		}
+		// distinct paths may join to the same key, e.g. `a.b` and `a` > `b`
+		seen := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			if seen[field.key] {
+				return nil, ParseError(fmt.Sprintf("Warn: Unable to flatten conflicting key %q", field.key))
+			}
+			seen[field.key] = true
+		}
+		return joinObject(fields)
+	})
+}
+
+// PrepareFlatten parses the flatten spec ahead of transforming any data.
+func PrepareFlatten(spec *Config) error {
+	return spec.prepareWith(parseFlattenSpec)
+}
+
+// Unflatten is the reverse of Flatten: it turns a single-level object with path keys
+// into a nested object.
+func Unflatten(spec *Config, data []byte) ([]byte, error) {
+	spec = spec.orEmpty()
+	parsed, err := spec.parsedSpec(parseFlattenSpec)
+	if err != nil {
+		return nil, err
+	}
+	f := parsed.(*flattenSpec)
+	return transformObjects(spec, data, f.path, f.targetPath, f.unflatten)
+}
+
+// PrepareUnflatten parses the unflatten spec ahead of transforming any data.
+func PrepareUnflatten(spec *Config) error {
+	return spec.prepareWith(parseFlattenSpec)
+}
+
+func parseFlattenSpec(spec *Config) (interface{}, error) {
+	f := &flattenSpec{path: "$", separator: spec.KeySeparator, arrays: arraysDot}
+	for name, field := range map[string]*string{"path": &f.path, "targetPath": &f.targetPath, "separator": &f.separator} {
+		if v, ok := (*spec.Spec)[name]; ok {
+			if *field, ok = v.(string); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a string", name))
+			}
+		}
+	}
+	if f.separator == "" {
+		return nil, SpecError("Warn: Invalid spec. \"separator\" must not be empty")
+	}
+	if arrays, ok := (*spec.Spec)["arrays"]; ok {
+		switch arrays {
+		case arraysDot, arraysBracket, arraysNone:
+			f.arrays = arrays.(string)
+		default:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown arrays style: %v", arrays))
+		}
+	}
+	if depth, ok := (*spec.Spec)["depth"]; ok {
+		depthFloat, ok := depth.(float64)
+		if !ok || depthFloat < 0 || depthFloat != float64(int(depthFloat)) {
+			return nil, SpecError("Warn: Invalid spec. \"depth\" must be a non-negative integer")
+		}
+		f.depth = int(depthFloat)
+	}
+	return f, nil
+}
+
+// flatten appends the flattened fields of value, found at key prefix, to fields.
+// level is the number of keys joined so far.
+func (f *flattenSpec) flatten(value []byte, prefix string, level int, fields *[]rawField) error {
+	expand := level == 0 || f.depth == 0 || level <= f.depth
+	switch {
+	case expand && value[0] == '{':
+		children, err := objectFields(value)
+		if err != nil {
+			return err
+		}
+		if len(children) == 0 && level > 0 {
+			break
+		}
+		for _, child := range children {
+			key := child.key
+			if level > 0 {
+				key = prefix + f.separator + child.key
+			}
+			if err := f.flatten(child.value, key, level+1, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	case expand && value[0] == '[' && f.arrays != arraysNone:
+		elements, err := arrayElements(value)
+		if err != nil {
+			return err
+		}
+		if len(elements) == 0 {
+			break
+		}
+		for i, element := range elements {
+			key := prefix + f.separator + strconv.Itoa(i)
+			if f.arrays == arraysBracket {
+				key = prefix + "[" + strconv.Itoa(i) + "]"
+			}
+			if err := f.flatten(element, key, level+1, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// empty objects and arrays, scalars and values beyond the depth limit are kept as-is
+	*fields = append(*fields, rawField{key: prefix, value: value})
+	return nil
+}
+
+// flatSegment is a segment of a flattened key: an object key or an array index.
+type flatSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// unflattenNode is a node of the object being rebuilt, either a leaf value, an object
+// with keys in order of first occurrence, or an array of elements by index.
+type unflattenNode struct {
+	value    []byte
+	keys     []string
+	children map[string]*unflattenNode
+	indexes  map[int]*unflattenNode
+}
+
+var bracketIndexRe = regexp.MustCompile(`\[(\d+)\]$`)
+
+func (f *flattenSpec) unflatten(object []byte) ([]byte, error) {
+	fields, err := objectFields(object)
+	if err != nil {
+		return nil, err
+	}
+	root := &unflattenNode{}
+	for _, field := range fields {
+		if err := root.insert(f.segments(field.key), field.value, field.key); err != nil {
+			return nil, err
+		}
+	}
+	return root.encode()
+}
+
+// segments splits a flattened key into its segments, up to the depth limit.
+func (f *flattenSpec) segments(key string) []flatSegment {
+	parts := strings.Split(key, f.separator)
+	if f.depth > 0 {
+		parts = strings.SplitN(key, f.separator, f.depth+1)
+	}
+	var segments []flatSegment
+	for _, part := range parts {
+		switch f.arrays {
+		case arraysDot:
+			if index, ok := parseIndex(part); ok && len(segments) > 0 {
+				segments = append(segments, flatSegment{index: index, isIndex: true})
+				continue
+			}
+		case arraysBracket:
+			// trailing `[n]` suffixes, as in `matrix[0][1]`
+			var indexes []flatSegment
+			for {
+				match := bracketIndexRe.FindStringSubmatchIndex(part)
+				// a top-level key is never an index
+				if match == nil || (match[0] == 0 && len(segments) == 0) {
+					break
+				}
+				index, ok := parseIndex(part[match[2]:match[3]])
+				if !ok {
+					break
+				}
+				indexes = append([]flatSegment{{index: index, isIndex: true}}, indexes...)
+				part = part[:match[0]]
+			}
+			if part != "" || len(indexes) == 0 {
+				segments = append(segments, flatSegment{key: part})
+			}
+			segments = append(segments, indexes...)
+			continue
+		}
+		segments = append(segments, flatSegment{key: part})
+	}
+	return segments
+}
+
+// parseIndex parses an array index written without sign or leading zeros.
+func parseIndex(s string) (int, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(s)
+	return index, err == nil
+}
+
+// insert sets value at the path of segments below n. key is the flattened key, for
+// error messages.
+func (n *unflattenNode) insert(segments []flatSegment, value []byte, key string) error {
+	conflict := ParseError(fmt.Sprintf("Warn: Unable to unflatten conflicting key %q", key))
+	if len(segments) == 0 {
+		if n.value != nil || n.keys != nil || n.indexes != nil {
+			return conflict
+		}
+		n.value = value
+		return nil
+	}
+	if n.value != nil {
+		return conflict
+	}
+	segment := segments[0]
+	var child *unflattenNode
+	if segment.isIndex {
+		if n.keys != nil {
+			return conflict
+		}
+		if n.indexes == nil {
+			n.indexes = make(map[int]*unflattenNode)
+		}
+		if child = n.indexes[segment.index]; child == nil {
+			child = &unflattenNode{}
+			n.indexes[segment.index] = child
+		}
+	} else {
+		if n.indexes != nil {
+			return conflict
+		}
+		if n.children == nil {
+			n.children = make(map[string]*unflattenNode)
+		}
+		if child = n.children[segment.key]; child == nil {
+			child = &unflattenNode{}
+			n.children[segment.key] = child
+			n.keys = append(n.keys, segment.key)
+		}
+	}
+	return child.insert(segments[1:], value, key)
+}
+
+// encode returns the raw json of the node. Array elements are ordered by index; gaps
+// between indexes are not filled.
+func (n *unflattenNode) encode() ([]byte, error) {
+	if n.value != nil {
+		return n.value, nil
+	}
+	if n.indexes != nil {
+		indexes := make([]int, 0, len(n.indexes))
+		for index := range n.indexes {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		elements := make([][]byte, len(indexes))
+		for i, index := range indexes {
+			element, err := n.indexes[index].encode()
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return joinArray(elements), nil
+	}
+	fields := make([]rawField, len(n.keys))
+	for i, key := range n.keys {
+		value, err := n.children[key].encode()
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = rawField{key: key, value: value}
+	}
+	return joinObject(fields)
+}
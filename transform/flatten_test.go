@@ -0,0 +1,188 @@
+package transform
+
+import "testing"
+
+func TestFlatten(t *testing.T) {
+	jsonIn := `{"id":12345678901234567890,"user":{"name":"x","address":{"city":"Y","geo":[1.5,2]}},"tags":[{"k":"a"},"b"],"empty":{},"none":[]}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"defaults",
+			`{}`,
+			`{"id":12345678901234567890,"user.name":"x","user.address.city":"Y","user.address.geo.0":1.5,"user.address.geo.1":2,"tags.0.k":"a","tags.1":"b","empty":{},"none":[]}`,
+		},
+		{
+			"bracket indexes and separator",
+			`{"separator": "_", "arrays": "bracket"}`,
+			`{"id":12345678901234567890,"user_name":"x","user_address_city":"Y","user_address_geo[0]":1.5,"user_address_geo[1]":2,"tags[0]_k":"a","tags[1]":"b","empty":{},"none":[]}`,
+		},
+		{
+			"arrays kept",
+			`{"arrays": "none"}`,
+			`{"id":12345678901234567890,"user.name":"x","user.address.city":"Y","user.address.geo":[1.5,2],"tags":[{"k":"a"},"b"],"empty":{},"none":[]}`,
+		},
+		{
+			"depth limit",
+			`{"depth": 1}`,
+			`{"id":12345678901234567890,"user.name":"x","user.address":{"city":"Y","geo":[1.5,2]},"tags.0":{"k":"a"},"tags.1":"b","empty":{},"none":[]}`,
+		},
+		{
+			"sub-path to target path",
+			`{"path": "user", "targetPath": "flat"}`,
+			`{"id":12345678901234567890,"user":{"name":"x","address":{"city":"Y","geo":[1.5,2]}},"tags":[{"k":"a"},"b"],"empty":{},"none":[],"flat":{"name":"x","address.city":"Y","address.geo.0":1.5,"address.geo.1":2}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Flatten, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonIn  string
+		jsonOut string
+	}{
+		{
+			"dot indexes",
+			`{}`,
+			`{"id":12345678901234567890,"user.name":"x","user.geo.1":2,"user.geo.0":1.5,"tags.0.k":"a","0":"root"}`,
+			`{"id":12345678901234567890,"user":{"name":"x","geo":[1.5,2]},"tags":[{"k":"a"}],"0":"root"}`,
+		},
+		{
+			"bracket indexes and separator",
+			`{"separator": "_", "arrays": "bracket"}`,
+			`{"user_geo[0]":1.5,"m[0][1]":"b","m[0][0]":"a","tags[0]_k":"a","n.0":1,"[0]":"root"}`,
+			`{"user":{"geo":[1.5]},"m":[["a","b"]],"tags":[{"k":"a"}],"n.0":1,"[0]":"root"}`,
+		},
+		{
+			"no indexes",
+			`{"arrays": "none"}`,
+			`{"a.0":1,"a.1":2}`,
+			`{"a":{"0":1,"1":2}}`,
+		},
+		{
+			"depth limit and gaps",
+			`{"depth": 1}`,
+			`{"a.b.c":1,"a.d":2,"l.5":"x","l.2":"y"}`,
+			`{"a":{"b.c":1,"d":2},"l":["y","x"]}`,
+		},
+		{
+			"sub-path",
+			`{"path": "flat"}`,
+			`{"flat":{"a.b":1},"other.key":2}`,
+			`{"flat":{"a":{"b":1}},"other.key":2}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Unflatten, cfg, tc.jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	jsonIn := `{"a":{"b":[{"c":1},{"d":[true,null]}],"e":"f"},"g":{}}`
+	for _, arrays := range []string{"dot", "bracket"} {
+		cfg := getConfig(`{"arrays": "`+arrays+`"}`, false)
+		flat, err := getTransformTestWrapper(Flatten, cfg, jsonIn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cfg = getConfig(`{"arrays": "`+arrays+`"}`, false)
+		kazaamOut, err := getTransformTestWrapper(Unflatten, cfg, string(flat))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(kazaamOut) != jsonIn {
+			t.Error("Round trip does not match the input.")
+			t.Log("Expected:   ", jsonIn)
+			t.Log("Actual:     ", string(kazaamOut))
+		}
+	}
+}
+
+func TestUnflattenConflicts(t *testing.T) {
+	testCases := []string{
+		`{"a":1,"a.b":2}`,
+		`{"a.b":2,"a":1}`,
+		`{"a.0":1,"a.b":2}`,
+		`{"a.b":1,"a.0":2}`,
+	}
+
+	for _, jsonIn := range testCases {
+		cfg := getConfig(`{}`, false)
+		_, err := getTransformTestWrapper(Unflatten, cfg, jsonIn)
+		if err == nil {
+			t.Error("Should have thrown an error for conflicting keys.")
+			t.Log("Input:      ", jsonIn)
+		}
+	}
+}
+
+func TestFlattenConflicts(t *testing.T) {
+	testCases := []struct {
+		spec   string
+		jsonIn string
+	}{
+		{`{}`, `{"a.b":1,"a":{"b":2}}`},
+		{`{}`, `{"a":{"b":2},"a.b":1}`},
+		{`{}`, `{"a.0":1,"a":[2]}`},
+		{`{"arrays": "bracket"}`, `{"a[0]":1,"a":[2]}`},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, false)
+		_, err := getTransformTestWrapper(Flatten, cfg, tc.jsonIn)
+		if _, ok := err.(ParseError); !ok {
+			t.Error("Should have thrown a ParseError for conflicting keys.")
+			t.Log("Input:      ", tc.jsonIn)
+			t.Log("Error:      ", err)
+		}
+	}
+}
+
+func TestFlattenInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"separator": ""}`,
+		`{"separator": 1}`,
+		`{"path": 1}`,
+		`{"arrays": "dots"}`,
+		`{"depth": -1}`,
+		`{"depth": 1.5}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareFlatten(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid flatten spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
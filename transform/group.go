@@ -0,0 +1,177 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// keyBy collision policies
+const (
+	collisionFirst = "first"
+	collisionLast  = "last"
+	collisionError = "error"
+)
+
+// groupSpec describes how the elements of an array are keyed or grouped.
+type groupSpec struct {
+	path        string
+	targetPath  string
+	by          string
+	onCollision string
+}
+
+// KeyBy turns an array into an object of its elements, keyed by the value at a
+// sub-path of each element. The `onCollision` policy decides which element is kept
+// when several share a key.
+func KeyBy(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseKeyBySpec)
+	if err != nil {
+		return nil, err
+	}
+	g := parsed.(*groupSpec)
+	return transformArrays(spec, data, g.path, g.targetPath, func(array []byte) ([]byte, error) {
+		return g.keyBy(array, spec.Require, spec.KeySeparator)
+	})
+}
+
+// PrepareKeyBy parses the keyBy spec ahead of transforming any data.
+func PrepareKeyBy(spec *Config) error {
+	return spec.prepareWith(parseKeyBySpec)
+}
+
+// GroupBy turns an array into an object of arrays, grouping the elements by the
+// value at a sub-path of each element.
+func GroupBy(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseGroupBySpec)
+	if err != nil {
+		return nil, err
+	}
+	g := parsed.(*groupSpec)
+	return transformArrays(spec, data, g.path, g.targetPath, func(array []byte) ([]byte, error) {
+		return g.groupBy(array, spec.Require, spec.KeySeparator)
+	})
+}
+
+// PrepareGroupBy parses the groupBy spec ahead of transforming any data.
+func PrepareGroupBy(spec *Config) error {
+	return spec.prepareWith(parseGroupBySpec)
+}
+
+func parseKeyBySpec(spec *Config) (interface{}, error) {
+	g, err := newGroupSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	g.onCollision = collisionLast
+	if onCollision, ok := (*spec.Spec)["onCollision"]; ok {
+		switch onCollision {
+		case collisionFirst, collisionLast, collisionError:
+			g.onCollision = onCollision.(string)
+		default:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown onCollision: %v", onCollision))
+		}
+	}
+	return g, nil
+}
+
+func parseGroupBySpec(spec *Config) (interface{}, error) {
+	return newGroupSpec(spec)
+}
+
+func newGroupSpec(spec *Config) (*groupSpec, error) {
+	g := &groupSpec{}
+	var ok bool
+	if g.path, ok = (*spec.Spec)["path"].(string); !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"path\"")
+	}
+	if g.by, ok = (*spec.Spec)["by"].(string); !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"by\"")
+	}
+	if targetPath, ok := (*spec.Spec)["targetPath"]; ok {
+		if g.targetPath, ok = targetPath.(string); !ok {
+			return nil, SpecError("Warn: Invalid spec. \"targetPath\" must be a string")
+		}
+	}
+	return g, nil
+}
+
+// keyBy returns the raw object of the elements of array by key. Keys are in order of
+// first occurrence.
+func (g *groupSpec) keyBy(array []byte, require bool, keySeparator string) ([]byte, error) {
+	elements, err := arrayElements(array)
+	if err != nil {
+		return nil, err
+	}
+	var fields []rawField
+	index := make(map[string]int)
+	for _, element := range elements {
+		key, ok, err := g.key(element, require, keySeparator)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		i, exists := index[key]
+		switch {
+		case !exists:
+			index[key] = len(fields)
+			fields = append(fields, rawField{key: key, value: element})
+		case g.onCollision == collisionLast:
+			fields[i].value = element
+		case g.onCollision == collisionError:
+			return nil, ParseError(fmt.Sprintf("Warn: Duplicate key %q at %s", key, g.by))
+		}
+	}
+	return joinObject(fields)
+}
+
+// groupBy returns the raw object of arrays of the elements of array by key. Groups are
+// in order of first occurrence and keep the order of their elements.
+func (g *groupSpec) groupBy(array []byte, require bool, keySeparator string) ([]byte, error) {
+	elements, err := arrayElements(array)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	groups := make(map[string][][]byte)
+	for _, element := range elements {
+		key, ok, err := g.key(element, require, keySeparator)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], element)
+	}
+	fields := make([]rawField, len(keys))
+	for i, key := range keys {
+		fields[i] = rawField{key: key, value: joinArray(groups[key])}
+	}
+	return joinObject(fields)
+}
+
+// key returns the object key for an element: the string form of the value at the
+// `by` path. Elements without a string, number or boolean value there have no key,
+// which is an error when require is set.
+func (g *groupSpec) key(element []byte, require bool, keySeparator string) (string, bool, error) {
+	raw, err := getJSONRaw(element, g.by, require, keySeparator)
+	if err != nil {
+		return "", false, err
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return "", false, err
+	}
+	switch value := normalizeNumbers(decoded).(type) {
+	case string, int64, float64, bool:
+		return exprString(value), true, nil
+	}
+	if require {
+		return "", false, ParseError(fmt.Sprintf("Warn: Unable to use value at %s as a key: %s", g.by, raw))
+	}
+	return "", false, nil
+}
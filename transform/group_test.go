@@ -0,0 +1,100 @@
+package transform
+
+import "testing"
+
+func TestKeyBy(t *testing.T) {
+	jsonIn := `{"users":[{"id":"b","n":1},{"id":"a","n":2},{"id":"b","n":3},{"n":4},{"id":7,"n":5}]}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"last wins by default",
+			`{"path": "users", "by": "id"}`,
+			`{"users":{"b":{"id":"b","n":3},"a":{"id":"a","n":2},"7":{"id":7,"n":5}}}`,
+		},
+		{
+			"first wins to target path",
+			`{"path": "users", "by": "id", "onCollision": "first", "targetPath": "byId"}`,
+			`{"users":[{"id":"b","n":1},{"id":"a","n":2},{"id":"b","n":3},{"n":4},{"id":7,"n":5}],"byId":{"b":{"id":"b","n":1},"a":{"id":"a","n":2},"7":{"id":7,"n":5}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(KeyBy, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestKeyByErrors(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		require bool
+	}{
+		{`{"path": "users", "by": "id", "onCollision": "error"}`, false},
+		{`{"path": "users", "by": "missing"}`, true},
+		{`{"path": "missing", "by": "id"}`, true},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, tc.require)
+		_, err := getTransformTestWrapper(KeyBy, cfg, `{"users":[{"id":"a"},{"id":"a"}]}`)
+		if err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Spec:       ", tc.spec)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	spec := `{"path": "orders[*].items", "by": "category"}`
+	jsonIn := `{"orders":[{"items":[{"sku":1,"category":"toys"},{"sku":2,"category":"books"},{"sku":3,"category":"toys"},{"sku":4}]}]}`
+	jsonOut := `{"orders":[{"items":{"toys":[{"sku":1,"category":"toys"},{"sku":3,"category":"toys"}],"books":[{"sku":2,"category":"books"}]}}]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(GroupBy, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestGroupByInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"by": "a"}`,
+		`{"path": "a"}`,
+		`{"path": "a", "by": "b", "targetPath": 1}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareGroupBy(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid groupBy spec.")
+			t.Log("Spec:       ", spec)
+		}
+		if err := PrepareKeyBy(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid keyBy spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+
+	cfg := getConfig(`{"path": "a", "by": "b", "onCollision": "merge"}`, false)
+	if err := PrepareKeyBy(&cfg); err == nil {
+		t.Error("Should have thrown a SpecError for an unknown collision policy.")
+	}
+}
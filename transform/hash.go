@@ -0,0 +1,183 @@
+package transform
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// hashAlgorithms maps algorithm names to hash constructors. Keyed algorithms take
+// the registered key.
+var hashAlgorithms = map[string]func(key []byte) hash.Hash{
+	"sha256":      func(key []byte) hash.Hash { return sha256.New() },
+	"sha512":      func(key []byte) hash.Hash { return sha512.New() },
+	"md5":         func(key []byte) hash.Hash { return md5.New() },
+	"hmac-sha256": func(key []byte) hash.Hash { return hmac.New(sha256.New, key) },
+}
+
+// hashSpec describes the hash computed for a single target path.
+type hashSpec struct {
+	newHash  func(key []byte) hash.Hash
+	key      []byte
+	sources  []string
+	delim    string
+	salt     string
+	encoding string
+}
+
+// Hash replaces each target path with a hash of its value, or of the values of one or
+// more source paths, e.g. to pseudonymize personal data. Keyed algorithms such as
+// `hmac-sha256` use a key registered on the kazaam Config, which never appears in the
+// spec.
+func Hash(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseHashSpecs)
+	if err != nil {
+		return nil, err
+	}
+	// every hash is computed from the input data, before any result is set
+	var results []exprResult
+	for k, h := range parsed.(map[string]*hashSpec) {
+		targets, err := expandWildcards(data, k, false, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			sources := h.sources
+			if sources == nil {
+				sources = []string{target.path}
+			}
+			message, ok, err := h.message(spec, data, sources, target.indexes)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			results = append(results, exprResult{path: target.path, value: bookend([]byte(h.sum(message)), '"', '"')})
+		}
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareHash parses the hash spec, resolving registered keys, ahead of transforming
+// any data.
+func PrepareHash(spec *Config) error {
+	return spec.prepareWith(parseHashSpecs)
+}
+
+func parseHashSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*hashSpec)
+	for k, v := range *spec.Spec {
+		hashMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		h := &hashSpec{encoding: "hex"}
+		algorithm, ok := hashMap["algorithm"].(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"algorithm\" for key: %s", k))
+		}
+		if h.newHash, ok = hashAlgorithms[algorithm]; !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown algorithm %q for key: %s", algorithm, k))
+		}
+		keyName, hasKey := hashMap["key"]
+		switch {
+		case strings.HasPrefix(algorithm, "hmac-") && !hasKey:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Algorithm %s requires a \"key\" for key: %s", algorithm, k))
+		case hasKey && !strings.HasPrefix(algorithm, "hmac-"):
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Algorithm %s does not take a \"key\" for key: %s", algorithm, k))
+		case hasKey:
+			keyNameStr, ok := keyName.(string)
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"key\" must be a string for key: %s", k))
+			}
+			if h.key, ok = spec.HashKeys[keyNameStr]; !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Hash key %q is not registered for key: %s", keyNameStr, k))
+			}
+		}
+		if sources, ok := hashMap["sources"]; ok {
+			sourceList, ok := sources.([]interface{})
+			if !ok || len(sourceList) == 0 {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"sources\" must be a non-empty list of paths for key: %s", k))
+			}
+			for _, source := range sourceList {
+				path, ok := source.(string)
+				if !ok {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"sources\" must be a non-empty list of paths for key: %s", k))
+				}
+				h.sources = append(h.sources, path)
+			}
+		}
+		for name, field := range map[string]*string{"delim": &h.delim, "salt": &h.salt} {
+			if value, ok := hashMap[name]; ok {
+				if *field, ok = value.(string); !ok {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a string for key: %s", name, k))
+				}
+			}
+		}
+		if encoding, ok := hashMap["encoding"]; ok {
+			switch encoding {
+			case "hex", "base64":
+				h.encoding = encoding.(string)
+			default:
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown encoding %v for key: %s", encoding, k))
+			}
+		}
+		specs[k] = h
+	}
+	return specs, nil
+}
+
+// message joins the values of the source paths with the delimiter. Strings are used
+// as-is and other values in their canonical json encoding. ok is false when a source
+// is missing or null, in which case the target is left unchanged.
+func (h *hashSpec) message(spec *Config, data []byte, sources []string, indexes []int) (string, bool, error) {
+	values := make([]string, len(sources))
+	for i, source := range sources {
+		// wildcards shared with the target refer to the same elements
+		raw, err := getJSONRaw(data, fillWildcards(source, indexes), spec.Require, spec.KeySeparator)
+		if err != nil {
+			return "", false, err
+		}
+		decoded, err := decodeJSON(raw)
+		if err != nil {
+			return "", false, err
+		}
+		switch decodedTyped := normalizeNumbers(decoded).(type) {
+		case nil:
+			return "", false, nil
+		case string:
+			values[i] = decodedTyped
+		default:
+			encoded, err := encodeExprValue(decodedTyped)
+			if err != nil {
+				return "", false, err
+			}
+			values[i] = string(encoded)
+		}
+	}
+	return strings.Join(values, h.delim), true, nil
+}
+
+// sum returns the encoded hash of the salted message.
+func (h *hashSpec) sum(message string) string {
+	hasher := h.newHash(h.key)
+	hasher.Write([]byte(h.salt + message))
+	digest := hasher.Sum(nil)
+	if h.encoding == "base64" {
+		return base64.StdEncoding.EncodeToString(digest)
+	}
+	return hex.EncodeToString(digest)
+}
@@ -0,0 +1,88 @@
+package transform
+
+import "testing"
+
+func TestHash(t *testing.T) {
+	jsonIn := `{"email":"a@example.com","account":12345,"country":"US","prefs":{"b":2,"a":1},"none":null,"users":[{"email":"a@example.com"},{"email":"b@example.com"}]}`
+	testCases := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"sha256 in place", `{"email": {"algorithm": "sha256"}}`, `{"email":"08168cd80dfd534ab0f10af10f1303fe00af2d43ab5c1432360d137f8197e17a","account":12345,"country":"US","prefs":{"b":2,"a":1},"none":null,"users":[{"email":"a@example.com"},{"email":"b@example.com"}]}`},
+		{"md5 to another path", `{"emailHash": {"algorithm": "md5", "sources": ["email"]}}`, `{"email":"a@example.com","account":12345,"country":"US","prefs":{"b":2,"a":1},"none":null,"users":[{"email":"a@example.com"},{"email":"b@example.com"}],"emailHash":"b418773a2c51fb9777a1648346fa7394"}`},
+		{"salted base64", `{"email": {"algorithm": "sha256", "salt": "pepper", "encoding": "base64"}}`, `{"email":"uTPVmq7TehzzkM9dCBsrxzIRbqYp69j9JoNvS01ISfA=","account":12345,"country":"US","prefs":{"b":2,"a":1},"none":null,"users":[{"email":"a@example.com"},{"email":"b@example.com"}]}`},
+		{"sha512 of several sources", `{"id": {"algorithm": "sha512", "sources": ["account", "country"], "delim": "|"}}`, `{"email":"a@example.com","account":12345,"country":"US","prefs":{"b":2,"a":1},"none":null,"users":[{"email":"a@example.com"},{"email":"b@example.com"}],"id":"42d29cc06f13587ed8bd11d783bf55192ed1ae8deabdb7a412b5fe99c179e8485adb4976ed22bed1d67f2704393765e09eeea9c2c3c3e3c3442bf18e91123f6a"}`},
+		{"objects are hashed with sorted keys", `{"prefs": {"algorithm": "sha256"}}`, `{"email":"a@example.com","account":12345,"country":"US","prefs":"43258cff783fe7036d8a43033f830adfc60ec037382473548ac742b888292777","none":null,"users":[{"email":"a@example.com"},{"email":"b@example.com"}]}`},
+		{"null and missing values are left unchanged", `{"none": {"algorithm": "sha256"}, "missing": {"algorithm": "sha256"}}`, jsonIn},
+		{"wildcards", `{"users[*].email": {"algorithm": "sha256"}}`, `{"email":"a@example.com","account":12345,"country":"US","prefs":{"b":2,"a":1},"none":null,"users":[{"email":"08168cd80dfd534ab0f10af10f1303fe00af2d43ab5c1432360d137f8197e17a"},{"email":"e8f39b3e1382367d6d41ab34dc270d4e7533f978c9e9a775dfe2185b2f96b96c"}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Hash, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestHashWithKey(t *testing.T) {
+	jsonIn := `{"users":[{"email":"a@example.com"}]}`
+	jsonOut := `{"users":[{"email":"a@example.com","emailHash":"0607236cc2fc521ca815254262b7014cb54eb5488f266e4777158cc52a33cfe9"}]}`
+	spec := `{"users[*].emailHash": {"algorithm": "hmac-sha256", "key": "pii", "sources": ["users[*].email"]}}`
+
+	cfg := getConfig(spec, false)
+	cfg.HashKeys = map[string][]byte{"pii": []byte("secret")}
+	if err := PrepareHash(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kazaamOut, err := getTransformTestWrapper(Hash, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestHashRequire(t *testing.T) {
+	cfg := getConfig(`{"missing": {"algorithm": "sha256"}}`, true)
+	_, err := getTransformTestWrapper(Hash, cfg, `{"email":"a@example.com"}`)
+	if err == nil {
+		t.Error("Should have thrown an error for a required missing path.")
+	}
+}
+
+func TestHashInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"a": "sha256"}`,
+		`{"a": {"sources": ["b"]}}`,
+		`{"a": {"algorithm": "sha1"}}`,
+		`{"a": {"algorithm": "hmac-sha256"}}`,
+		`{"a": {"algorithm": "hmac-sha256", "key": "unknown"}}`,
+		`{"a": {"algorithm": "sha256", "key": "pii"}}`,
+		`{"a": {"algorithm": "sha256", "sources": []}}`,
+		`{"a": {"algorithm": "sha256", "sources": "b"}}`,
+		`{"a": {"algorithm": "sha256", "salt": 1}}`,
+		`{"a": {"algorithm": "sha256", "encoding": "base32"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		cfg.HashKeys = map[string][]byte{"pii": []byte("secret")}
+		if err := PrepareHash(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid hash spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// lookupSpec describes the table mapping the values at a single path.
+type lookupSpec struct {
+	table      map[string][]byte
+	fallback   []byte
+	strict     bool
+	targetPath string
+}
+
+// Lookup maps the value at each path through a table, e.g. to translate codes such as
+// `"M"` to `"male"`. Tables are either inline in the spec or registered by name on the
+// kazaam Config.
+func Lookup(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseLookupSpecs)
+	if err != nil {
+		return nil, err
+	}
+	// every value is looked up from the input data, before any result is set
+	var results []exprResult
+	for k, l := range parsed.(map[string]*lookupSpec) {
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			value, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			if string(value) == "null" {
+				continue
+			}
+			mapped, ok, err := l.lookup(value)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				if l.strict {
+					return nil, ParseError(fmt.Sprintf("Warn: Unmapped value %s at %s", value, p.path))
+				}
+				if l.fallback == nil && l.targetPath == "" {
+					continue
+				}
+				mapped = value
+				if l.fallback != nil {
+					mapped = l.fallback
+				}
+			}
+			targetPath := p.path
+			if l.targetPath != "" {
+				targetPath = fillWildcards(l.targetPath, p.indexes)
+			}
+			results = append(results, exprResult{path: targetPath, value: mapped})
+		}
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareLookup parses the lookup spec, resolving registered tables, ahead of
+// transforming any data.
+func PrepareLookup(spec *Config) error {
+	return spec.prepareWith(parseLookupSpecs)
+}
+
+func parseLookupSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*lookupSpec)
+	for k, v := range *spec.Spec {
+		lookupMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		l := &lookupSpec{}
+		var table map[string]interface{}
+		switch tableTyped := lookupMap["table"].(type) {
+		case map[string]interface{}:
+			table = tableTyped
+		case string:
+			if table, ok = spec.LookupTables[tableTyped]; !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Lookup table %q is not registered for key: %s", tableTyped, k))
+			}
+		default:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"table\" must be an object or the name of a registered table for key: %s", k))
+		}
+		l.table = make(map[string][]byte, len(table))
+		for from, to := range table {
+			encoded, err := encodeJSON(to)
+			if err != nil {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to encode lookup value %v for key: %s", to, k))
+			}
+			l.table[from] = encoded
+		}
+		if fallback, ok := lookupMap["default"]; ok {
+			var err error
+			if l.fallback, err = encodeJSON(fallback); err != nil {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to encode default %v for key: %s", fallback, k))
+			}
+		}
+		if strict, ok := lookupMap["strict"]; ok {
+			if l.strict, ok = strict.(bool); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"strict\" must be a boolean for key: %s", k))
+			}
+		}
+		if l.strict && l.fallback != nil {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"strict\" cannot be combined with \"default\" for key: %s", k))
+		}
+		if targetPath, ok := lookupMap["targetPath"]; ok {
+			if l.targetPath, ok = targetPath.(string); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"targetPath\" must be a string for key: %s", k))
+			}
+		}
+		specs[k] = l
+	}
+	return specs, nil
+}
+
+// lookup returns the mapped raw value. Strings, numbers and booleans are looked up by
+// their string form; arrays and objects are never mapped.
+func (l *lookupSpec) lookup(value []byte) ([]byte, bool, error) {
+	if value[0] == '[' || value[0] == '{' {
+		return nil, false, nil
+	}
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		return nil, false, err
+	}
+	mapped, ok := l.table[exprString(normalizeNumbers(decoded))]
+	return mapped, ok, nil
+}
@@ -0,0 +1,84 @@
+package transform
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"inline table", `{"gender": {"table": {"M": "male", "F": "female"}}}`, `{"gender":"F"}`, `{"gender":"female"}`},
+		{"any mapped value", `{"status": {"table": {"1": {"code": "OPEN", "active": true}}}}`, `{"status":1}`, `{"status":{"active":true,"code":"OPEN"}}`},
+		{"booleans by string form", `{"flag": {"table": {"true": "Y", "false": "N"}}}`, `{"flag":false}`, `{"flag":"N"}`},
+		{"unmapped values are kept", `{"gender": {"table": {"M": "male"}}}`, `{"gender":"X"}`, `{"gender":"X"}`},
+		{"default", `{"gender": {"table": {"M": "male"}, "default": "unknown"}}`, `{"gender":"X"}`, `{"gender":"unknown"}`},
+		{"null default", `{"gender": {"table": {"M": "male"}, "default": null}}`, `{"gender":"X"}`, `{"gender":null}`},
+		{"objects are unmapped", `{"gender": {"table": {"M": "male"}, "default": "unknown"}}`, `{"gender":{"M":1}}`, `{"gender":"unknown"}`},
+		{"target path", `{"gender": {"table": {"M": "male"}, "targetPath": "genderName"}}`, `{"gender":"M"}`, `{"gender":"M","genderName":"male"}`},
+		{"unmapped values are copied to the target path", `{"gender": {"table": {"M": "male"}, "targetPath": "genderName"}}`, `{"gender":"X"}`, `{"gender":"X","genderName":"X"}`},
+		{"null and missing values are left unchanged", `{"a": {"table": {}, "default": 1}, "b": {"table": {}, "default": 1}}`, `{"a":null}`, `{"a":null}`},
+		{"wildcards", `{"people[*].sex": {"table": {"M": "male", "F": "female"}, "targetPath": "people[*].gender"}}`, `{"people":[{"sex":"M"},{"sex":"F"}]}`, `{"people":[{"sex":"M","gender":"male"},{"sex":"F","gender":"female"}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Lookup, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestLookupRegisteredTable(t *testing.T) {
+	cfg := getConfig(`{"country": {"table": "iso3", "strict": true}}`, false)
+	cfg.LookupTables = map[string]map[string]interface{}{"iso3": {"US": "USA", "DE": "DEU"}}
+	if err := PrepareLookup(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kazaamOut, err := getTransformTestWrapper(Lookup, cfg, `{"country":"US"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != `{"country":"USA"}` {
+		t.Errorf("Unexpected output: %s", kazaamOut)
+	}
+	if _, err := getTransformTestWrapper(Lookup, cfg, `{"country":"FR"}`); err == nil {
+		t.Error("Should have thrown an error for an unmapped value in strict mode.")
+	}
+}
+
+func TestLookupRequire(t *testing.T) {
+	cfg := getConfig(`{"missing": {"table": {"M": "male"}}}`, true)
+	if _, err := getTransformTestWrapper(Lookup, cfg, `{"gender":"M"}`); err == nil {
+		t.Error("Should have thrown an error for a required missing path.")
+	}
+}
+
+func TestLookupInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"a": "table"}`,
+		`{"a": {}}`,
+		`{"a": {"table": ["M"]}}`,
+		`{"a": {"table": "unregistered"}}`,
+		`{"a": {"table": {}, "strict": "yes"}}`,
+		`{"a": {"table": {}, "strict": true, "default": "x"}}`,
+		`{"a": {"table": {}, "targetPath": 1}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareLookup(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid lookup spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
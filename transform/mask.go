@@ -0,0 +1,295 @@
+package transform
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maskDetector finds a kind of sensitive data within strings. Matches of re are
+// masked when valid, if set, accepts them.
+type maskDetector struct {
+	re    *regexp.Regexp
+	valid func(match string) bool
+	// emails only mask their local part
+	email bool
+}
+
+var maskDetectors = map[string]*maskDetector{
+	"email":      {re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), email: true},
+	"creditCard": {re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid},
+	"ssn":        {re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), valid: ssnValid},
+	"ipv4":       {re: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), valid: ipValid},
+	"ipv6":       {re: regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}(?:(?:\d{1,3}\.){3}\d{1,3}|[0-9a-f]{0,4})`), valid: ipv6Valid},
+	"phone":      {re: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\)|\b\d{3})[ .-]?\d{3}[ .-]?\d{4}\b`)},
+}
+
+// maskSpec describes how the value at a single target path is masked.
+type maskSpec struct {
+	keepFirst   int
+	keepLast    int
+	maskChar    string
+	maskLength  int
+	replacement *string
+	detectors   []*maskDetector
+	recursive   bool
+}
+
+// Mask hides all or part of the strings at each target path, e.g. `****1234` for a
+// card number. With detectors, only the matches of the detectors, such as email
+// addresses within free text, are masked, and with `recursive` every string below the
+// path is.
+func Mask(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseMaskSpecs)
+	if err != nil {
+		return nil, err
+	}
+	// every value is masked from the input data, before any result is set. The whole
+	// message is set first, so that the rules of other paths take precedence over `$`
+	var results []exprResult
+	for k, m := range parsed.(map[string]*maskSpec) {
+		if k == "$" {
+			masked, err := m.maskValue(data)
+			if err != nil {
+				return nil, err
+			}
+			results = append([]exprResult{{path: k, value: masked}}, results...)
+			continue
+		}
+		targets, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			value, err := getJSONRaw(data, target.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			if string(value) == "null" {
+				continue
+			}
+			masked, err := m.maskValue(value)
+			if err != nil {
+				return nil, ParseError(fmt.Sprintf("%v at %s", err, target.path))
+			}
+			results = append(results, exprResult{path: target.path, value: masked})
+		}
+	}
+	for _, result := range results {
+		if result.path == "$" {
+			data = result.value
+			continue
+		}
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareMask parses the mask spec ahead of transforming any data.
+func PrepareMask(spec *Config) error {
+	return spec.prepareWith(parseMaskSpecs)
+}
+
+func parseMaskSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*maskSpec)
+	for k, v := range *spec.Spec {
+		maskMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		m := &maskSpec{maskChar: "*", maskLength: -1}
+		masking := false
+		for name, option := range maskMap {
+			switch name {
+			case "keepFirst", "keepLast", "maskLength":
+				n, ok := nonNegativeInt(option)
+				if !ok {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a non-negative integer for key: %s", name, k))
+				}
+				switch name {
+				case "keepFirst":
+					m.keepFirst = n
+				case "keepLast":
+					m.keepLast = n
+				default:
+					m.maskLength = n
+				}
+				masking = true
+			case "maskChar":
+				maskChar, ok := option.(string)
+				if !ok || utf8.RuneCountInString(maskChar) != 1 {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"maskChar\" must be a single character for key: %s", k))
+				}
+				m.maskChar = maskChar
+				masking = true
+			case "replacement":
+				replacement, ok := option.(string)
+				if !ok {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"replacement\" must be a string for key: %s", k))
+				}
+				m.replacement = &replacement
+			case "detectors":
+				detectorList, ok := option.([]interface{})
+				if !ok || len(detectorList) == 0 {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"detectors\" must be a non-empty list for key: %s", k))
+				}
+				for _, name := range detectorList {
+					detector, ok := maskDetectors[fmt.Sprint(name)]
+					if !ok {
+						return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown detector %v for key: %s", name, k))
+					}
+					m.detectors = append(m.detectors, detector)
+				}
+			case "recursive":
+				if m.recursive, ok = option.(bool); !ok {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"recursive\" must be a boolean for key: %s", k))
+				}
+			default:
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown option %q for key: %s", name, k))
+			}
+		}
+		if masking && m.replacement != nil {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"replacement\" cannot be combined with keepFirst, keepLast, maskChar or maskLength for key: %s", k))
+		}
+		specs[k] = m
+	}
+	return specs, nil
+}
+
+// nonNegativeInt converts a spec value to a non-negative integer.
+func nonNegativeInt(v interface{}) (int, bool) {
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// maskValue returns the raw masked value. Numbers are masked as strings; in recursive
+// mode, every string of an array or object is masked and other values are kept.
+func (m *maskSpec) maskValue(value []byte) ([]byte, error) {
+	switch {
+	case value[0] == '"' || (!m.recursive && isRawNumber(value)):
+		decoded, err := decodeJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeJSON(m.maskString(fmt.Sprint(decoded)))
+	case !m.recursive:
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to mask non-string value %s without \"recursive\"", value))
+	case value[0] == '[':
+		elements, err := arrayElements(value)
+		if err != nil {
+			return nil, err
+		}
+		for i, element := range elements {
+			if elements[i], err = m.maskValue(element); err != nil {
+				return nil, err
+			}
+		}
+		return joinArray(elements), nil
+	case value[0] == '{':
+		fields, err := objectFields(value)
+		if err != nil {
+			return nil, err
+		}
+		for i, field := range fields {
+			if fields[i].value, err = m.maskValue(field.value); err != nil {
+				return nil, err
+			}
+		}
+		return joinObject(fields)
+	}
+	return value, nil
+}
+
+// isRawNumber reports whether a raw json value is a number.
+func isRawNumber(value []byte) bool {
+	return value[0] == '-' || (value[0] >= '0' && value[0] <= '9')
+}
+
+// maskString masks the whole string, or only the matches of the detectors.
+func (m *maskSpec) maskString(s string) string {
+	if len(m.detectors) == 0 {
+		return m.mask(s)
+	}
+	for _, detector := range m.detectors {
+		detector := detector
+		s = detector.re.ReplaceAllStringFunc(s, func(match string) string {
+			if detector.valid != nil && !detector.valid(match) {
+				return match
+			}
+			if at := strings.LastIndex(match, "@"); detector.email && m.replacement == nil {
+				return m.mask(match[:at]) + match[at:]
+			}
+			return m.mask(match)
+		})
+	}
+	return s
+}
+
+// mask replaces the characters of s between the kept first and last characters. When
+// s is too short to keep them, every character is masked.
+func (m *maskSpec) mask(s string) string {
+	if m.replacement != nil {
+		return *m.replacement
+	}
+	runes := []rune(s)
+	keepFirst, keepLast := m.keepFirst, m.keepLast
+	if keepFirst+keepLast >= len(runes) {
+		keepFirst, keepLast = 0, 0
+	}
+	maskLength := m.maskLength
+	if maskLength < 0 {
+		maskLength = len(runes) - keepFirst - keepLast
+	}
+	return string(runes[:keepFirst]) + strings.Repeat(m.maskChar, maskLength) + string(runes[len(runes)-keepLast:])
+}
+
+// luhnValid reports whether the digits of a card number pass the Luhn checksum.
+func luhnValid(match string) bool {
+	sum := 0
+	double := false
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ssnValid rejects the area, group and serial numbers never issued as US SSNs.
+func ssnValid(match string) bool {
+	area, group, serial := match[:3], match[4:6], match[7:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func ipValid(match string) bool {
+	return net.ParseIP(match) != nil
+}
+
+// ipv6Valid also requires two non-empty groups, so that e.g. the `d::` of
+// `std::string` is not taken for an address.
+func ipv6Valid(match string) bool {
+	groups := 0
+	for _, group := range strings.Split(match, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 2 && ipValid(match)
+}
@@ -0,0 +1,108 @@
+package transform
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"keep last", `{"card": {"keepLast": 4}}`, `{"card":"4111111111111111"}`, `{"card":"************1111"}`},
+		{"fixed mask length", `{"card": {"keepLast": 4, "maskLength": 4}}`, `{"card":"4111111111111111"}`, `{"card":"****1111"}`},
+		{"keep first and last with mask char", `{"name": {"keepFirst": 1, "keepLast": 1, "maskChar": "#"}}`, `{"name":"Jörgen"}`, `{"name":"J####n"}`},
+		{"short values are fully masked", `{"pin": {"keepFirst": 2, "keepLast": 2}}`, `{"pin":"123"}`, `{"pin":"***"}`},
+		{"numbers are masked as strings", `{"account": {"keepLast": 2}}`, `{"account":1234567}`, `{"account":"*****67"}`},
+		{"replacement", `{"secret": {"replacement": "[REDACTED]"}}`, `{"secret":"hunter2"}`, `{"secret":"[REDACTED]"}`},
+		{"null and missing values are left unchanged", `{"a": {"keepLast": 2}, "b": {"keepLast": 2}}`, `{"a":null}`, `{"a":null}`},
+		{"wildcards", `{"users[*].ssn": {"keepLast": 4}}`, `{"users":[{"ssn":"123-45-6789"},{"ssn":"987-65-4321"}]}`, `{"users":[{"ssn":"*******6789"},{"ssn":"*******4321"}]}`},
+		{"email detector masks the local part", `{"email": {"detectors": ["email"], "keepFirst": 1, "maskLength": 3}}`, `{"email":"john.doe@example.com"}`, `{"email":"j***@example.com"}`},
+		{"email detector with replacement", `{"note": {"detectors": ["email"], "replacement": "<email>"}}`, `{"note":"mail a@b.io or c@d.org"}`, `{"note":"mail <email> or <email>"}`},
+		{"credit card detector checks luhn", `{"note": {"detectors": ["creditCard"], "keepLast": 4}}`, `{"note":"paid 4111 1111 1111 1111, not 4111 1111 1111 1112"}`, `{"note":"paid ***************1111, not 4111 1111 1111 1112"}`},
+		{"ssn detector", `{"note": {"detectors": ["ssn"], "replacement": "XXX"}}`, `{"note":"ssn 123-45-6789 or 000-12-3456"}`, `{"note":"ssn XXX or 000-12-3456"}`},
+		{"ip detectors", `{"log": {"detectors": ["ipv4", "ipv6"], "replacement": "<ip>"}}`, `{"log":"from 10.0.0.1 and fe80::1:2, not 999.1.1.1 or std::string"}`, `{"log":"from <ip> and <ip>, not 999.1.1.1 or std::string"}`},
+		{"phone detector", `{"note": {"detectors": ["phone"], "keepLast": 2}}`, `{"note":"call (555) 123-4567 or +1 555.123.4567"}`, `{"note":"call ************67 or *************67"}`},
+		{"recursive", `{"$": {"recursive": true, "detectors": ["email"], "replacement": "<email>"}}`, `{"from":"a@b.io","to":["c@d.org",{"cc":"hi e@f.net"}],"n":1}`, `{"from":"<email>","to":["<email>",{"cc":"hi <email>"}],"n":1}`},
+		{"recursive below a path", `{"user": {"recursive": true, "keepFirst": 1}}`, `{"user":{"name":"ann","age":30},"id":"x1"}`, `{"user":{"name":"a**","age":30},"id":"x1"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Mask, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestMaskWholeMessageWithPaths(t *testing.T) {
+	spec := `{"$": {"recursive": true, "replacement": "x"}, "card": {"keepLast": 4}, "user.name": {"keepFirst": 1}}`
+	jsonIn := `{"card":"4111111111111111","note":"hi","user":{"name":"ann"}}`
+	jsonOut := `{"card":"************1111","note":"x","user":{"name":"a**"}}`
+
+	// the order of the rules must not depend on the iteration order of the spec
+	for i := 0; i < 20; i++ {
+		cfg := getConfig(spec, false)
+		kazaamOut, err := getTransformTestWrapper(Mask, cfg, jsonIn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(kazaamOut) != jsonOut {
+			t.Error("Transformed data does not match expectation.")
+			t.Log("Expected:   ", jsonOut)
+			t.Log("Actual:     ", string(kazaamOut))
+			t.FailNow()
+		}
+	}
+}
+
+func TestMaskErrors(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		require bool
+	}{
+		{`{"user": {"keepLast": 4}}`, false},
+		{`{"flag": {"keepLast": 4}}`, false},
+		{`{"missing": {"keepLast": 4}}`, true},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, tc.require)
+		_, err := getTransformTestWrapper(Mask, cfg, `{"user":{"name":"ann"},"flag":true}`)
+		if err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Spec:       ", tc.spec)
+		}
+	}
+}
+
+func TestMaskInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"a": "****"}`,
+		`{"a": {"keepLast": -1}}`,
+		`{"a": {"keepFirst": 1.5}}`,
+		`{"a": {"maskChar": "**"}}`,
+		`{"a": {"replacement": 1}}`,
+		`{"a": {"replacement": "x", "keepLast": 4}}`,
+		`{"a": {"detectors": []}}`,
+		`{"a": {"detectors": ["iban"]}}`,
+		`{"a": {"recursive": "yes"}}`,
+		`{"a": {"keep": 4}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareMask(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid mask spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// Math sets each target path to the result of a numeric expression over source
+// paths and literals, e.g. `{"total": "price * quantity"}`. Expressions are parsed
+// once per spec, see PrepareMath.
+func Math(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseExprSpecs)
+	if err != nil {
+		return nil, err
+	}
+	return setExprTargets(spec, data, parsed.(map[string]*Expression), func(k string, result interface{}) error {
+		if result != nil && !isNumber(result) {
+			return ParseError(fmt.Sprintf("Warn: Expression for key %s did not evaluate to a number", k))
+		}
+		return nil
+	})
+}
+
+// PrepareMath parses the expressions of the math spec ahead of transforming any data.
+func PrepareMath(spec *Config) error {
+	return spec.prepareWith(parseExprSpecs)
+}
@@ -0,0 +1,138 @@
+package transform
+
+import "testing"
+
+func TestMath(t *testing.T) {
+	jsonIn := `{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77]}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"multiply",
+			`{"total": "price * quantity"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77],"total":50}`,
+		},
+		{
+			"precedence and parentheses",
+			`{"a": "1 + 2 * 3", "b": "(1 + 2) * 3", "c": "-quantity + 10 % 4"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77],"a":7,"b":9,"c":-2}`,
+		},
+		{
+			"division and rounding",
+			`{"dollars": "cents / 100", "tax": "round(price * rate, 2)", "half": "quantity / 2"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77],"dollars":19.99,"tax":0.91,"half":2}`,
+		},
+		{
+			"aggregate functions over wildcards skip nulls",
+			`{"sum": "sum(scores[*])", "avg": "avg(scores[*])", "min": "min(scores[*])", "max": "max(scores[*], 100)"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77],"sum":252,"avg":84,"min":77,"max":100}`,
+		},
+		{
+			"per element wildcard target",
+			`{"items[*].total": "items[*].price * items[*].qty"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3,"total":6},{"price":1.25,"qty":2,"total":2.5}],"scores":[90,85,null,77]}`,
+		},
+		{
+			"rounding functions",
+			`{"a": "floor(price)", "b": "ceil(price)", "c": "round(price)", "d": "abs(0 - cents)", "e": "round(cents, -2)"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77],"a":12,"b":13,"c":13,"d":1999,"e":2000}`,
+		},
+		{
+			"missing paths yield null",
+			`{"total": "missing * quantity"}`,
+			`{"price":12.5,"quantity":4,"cents":1999,"rate":0.0725,"items":[{"price":2,"qty":3},{"price":1.25,"qty":2}],"scores":[90,85,null,77],"total":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Math, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(tc.jsonOut))
+			if !areEqual {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestMathIntegerPrecision(t *testing.T) {
+	spec := `{"next": "id + 1", "scaled": "` + "`odd.key`" + ` * 2"}`
+	jsonIn := `{"id":9007199254740993,"odd.key":4611686018427387903}`
+	jsonOut := `{"id":9007199254740993,"odd.key":4611686018427387903,"next":9007199254740994,"scaled":9223372036854775806}`
+
+	cfg := getConfig(spec, false)
+	// quoted paths are used verbatim with the configured key separator
+	cfg.KeySeparator = "|"
+	kazaamOut, err := getTransformTestWrapper(Math, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual || len(kazaamOut) != len(jsonOut) {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	testCases := []string{
+		`{"x": "quantity / 0"}`,
+		`{"x": "price % 0"}`,
+		`{"x": "big * 2"}`,
+		`{"x": "big + big"}`,
+		`{"x": "name * 2"}`,
+		`{"x": "sum(name)"}`,
+		`{"x": "items"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		_, err := getTransformTestWrapper(Math, cfg, `{"quantity":4,"price":1.5,"big":9223372036854775807,"name":"a","items":[1]}`)
+		if err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestMathRequire(t *testing.T) {
+	cfg := getConfig(`{"x": "missing + 1"}`, true)
+	_, err := getTransformTestWrapper(Math, cfg, `{"a":1}`)
+	if err == nil {
+		t.Error("Should have thrown an error with require.")
+	}
+}
+
+func TestMathInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"x": 1}`,
+		`{"x": ""}`,
+		`{"x": "1 +"}`,
+		`{"x": "(1 + 2"}`,
+		`{"x": "1 2"}`,
+		`{"x": "a # b"}`,
+		`{"x": "nope(1)"}`,
+		`{"x": "abs(1, 2)"}`,
+		`{"x": "round()"}`,
+		`{"x": "` + "`a.b" + `"}`,
+		`{"x": "a[0"}`,
+		`{"x": "1.2.3"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareMath(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid math spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// merge strategies for objects
+const (
+	mergeStrategyShallow    = "shallow"
+	mergeStrategyDeep       = "deep"
+	mergeStrategyMergePatch = "mergePatch"
+)
+
+// merge strategies for arrays
+const (
+	mergeArraysReplace = "replace"
+	mergeArraysConcat  = "concat"
+	mergeArraysIndex   = "index"
+)
+
+// Merge combines the objects found at source paths and literal source values, in
+// order, and sets the result at the target path in raw []byte.
+func Merge(spec *Config, data []byte) ([]byte, error) {
+	sourceList, sourceOk := (*spec.Spec)["sources"].([]interface{})
+	if !sourceOk {
+		return nil, SpecError("Unable to get sources")
+	}
+	targetPath, targetOk := (*spec.Spec)["targetPath"].(string)
+	if !targetOk {
+		return nil, SpecError("Unable to get targetPath")
+	}
+	strategy, err := mergeSpecOption(spec, "strategy", mergeStrategyDeep, mergeStrategyShallow, mergeStrategyDeep, mergeStrategyMergePatch)
+	if err != nil {
+		return nil, err
+	}
+	arrays, err := mergeSpecOption(spec, "arrays", mergeArraysReplace, mergeArraysReplace, mergeArraysConcat, mergeArraysIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged interface{}
+	first := true
+	for _, vItem := range sourceList {
+		source, ok := vItem.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Error processing %v: source should be an object", vItem))
+		}
+		var value interface{}
+		if literal, ok := source["value"]; ok {
+			// merging happens in place, work on a copy so the spec is never modified
+			if value, err = copyJSONValue(literal); err != nil {
+				return nil, err
+			}
+		} else {
+			path, ok := source["path"].(string)
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Error processing %v: must have either value or path specified", vItem))
+			}
+			if value, err = mergeSourceValue(spec, data, path); err != nil {
+				return nil, err
+			}
+		}
+		// missing sources don't take part in the merge
+		if value == nil {
+			continue
+		}
+		if first {
+			merged = value
+			first = false
+			continue
+		}
+		merged = mergeValues(merged, value, strategy, arrays)
+	}
+	// nothing to merge, leave the data untouched
+	if first {
+		return data, nil
+	}
+
+	out, err := encodeJSON(merged)
+	if err != nil {
+		return nil, err
+	}
+	if targetPath == "$" {
+		return out, nil
+	}
+	return setJSONRaw(data, out, targetPath, spec.KeySeparator)
+}
+
+// mergeSpecOption reads an optional string field of the spec, validating it against
+// the allowed values.
+func mergeSpecOption(spec *Config, field, defaultValue string, allowed ...string) (string, error) {
+	optionInterface, ok := (*spec.Spec)[field]
+	if !ok {
+		return defaultValue, nil
+	}
+	option, ok := optionInterface.(string)
+	if ok {
+		for _, a := range allowed {
+			if option == a {
+				return option, nil
+			}
+		}
+	}
+	return "", SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown %s: %v", field, optionInterface))
+}
+
+// mergeSourceValue decodes the value at path, with `$` referring to the whole document.
+func mergeSourceValue(spec *Config, data []byte, path string) (interface{}, error) {
+	raw := data
+	if path != "$" {
+		var err error
+		raw, err = getJSONRaw(data, path, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decodeJSON(raw)
+}
+
+// mergeValues merges src into dst according to the object strategy and, for the
+// shallow and deep strategies, the array strategy. dst may be modified in place.
+func mergeValues(dst, src interface{}, strategy, arrays string) interface{} {
+	if strategy == mergeStrategyMergePatch {
+		return mergePatch(dst, src)
+	}
+
+	switch srcTyped := src.(type) {
+	case map[string]interface{}:
+		dstMap, ok := dst.(map[string]interface{})
+		if !ok {
+			return src
+		}
+		for k, v := range srcTyped {
+			if existing, ok := dstMap[k]; ok && strategy == mergeStrategyDeep {
+				dstMap[k] = mergeValues(existing, v, strategy, arrays)
+			} else {
+				dstMap[k] = v
+			}
+		}
+		return dstMap
+	case []interface{}:
+		dstArray, ok := dst.([]interface{})
+		if !ok {
+			return src
+		}
+		switch arrays {
+		case mergeArraysConcat:
+			return append(dstArray, srcTyped...)
+		case mergeArraysIndex:
+			for i, v := range srcTyped {
+				if i >= len(dstArray) {
+					dstArray = append(dstArray, v)
+				} else if strategy == mergeStrategyDeep {
+					dstArray[i] = mergeValues(dstArray[i], v, strategy, arrays)
+				} else {
+					dstArray[i] = v
+				}
+			}
+			return dstArray
+		}
+	}
+	return src
+}
+
+// mergePatch applies patch to target following RFC 7386 JSON Merge Patch: objects
+// are merged recursively, null removes a key and any other value replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		targetMap = map[string]interface{}{}
+	}
+	for k, v := range patchMap {
+		if v == nil {
+			delete(targetMap, k)
+		} else {
+			targetMap[k] = mergePatch(targetMap[k], v)
+		}
+	}
+	return targetMap
+}
+
+// deepMerge merges src into dst. Objects are merged key by key, recursively; any
+// other value in src replaces the value in dst.
+func deepMerge(dst, src interface{}) interface{} {
+	return mergeValues(dst, src, mergeStrategyDeep, mergeArraysReplace)
+}
+
+// copyJSONValue returns a deep copy of a decoded json value.
+func copyJSONValue(v interface{}) (interface{}, error) {
+	raw, err := encodeJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(raw)
+}
@@ -0,0 +1,115 @@
+package transform
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	jsonIn := `{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"settings":{"alerts":{"sms":true},"tags":["c"]}}}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"deep",
+			`{"sources": [{"path": "defaults.settings"}, {"path": "user.settings"}], "targetPath": "user.settings"}`,
+			`{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"settings":{"theme":"light","alerts":{"email":true,"sms":true},"tags":["c"]}}}`,
+		},
+		{
+			"shallow",
+			`{"sources": [{"path": "defaults.settings"}, {"path": "user.settings"}], "targetPath": "merged", "strategy": "shallow"}`,
+			`{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"settings":{"alerts":{"sms":true},"tags":["c"]}},"merged":{"theme":"light","alerts":{"sms":true},"tags":["c"]}}`,
+		},
+		{
+			"deep with array concat",
+			`{"sources": [{"path": "defaults.settings"}, {"path": "user.settings"}], "targetPath": "merged", "arrays": "concat"}`,
+			`{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"settings":{"alerts":{"sms":true},"tags":["c"]}},"merged":{"theme":"light","alerts":{"email":true,"sms":true},"tags":["a","b","c"]}}`,
+		},
+		{
+			"deep with array index",
+			`{"sources": [{"path": "defaults.settings"}, {"path": "user.settings"}], "targetPath": "merged", "arrays": "index"}`,
+			`{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"settings":{"alerts":{"sms":true},"tags":["c"]}},"merged":{"theme":"light","alerts":{"email":true,"sms":true},"tags":["c","b"]}}`,
+		},
+		{
+			"merge patch",
+			`{"sources": [{"path": "defaults.settings"}, {"value": {"theme": null, "alerts": {"sms": null, "push": true}}}], "targetPath": "merged", "strategy": "mergePatch"}`,
+			`{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"settings":{"alerts":{"sms":true},"tags":["c"]}},"merged":{"alerts":{"email":true,"push":true},"tags":["a","b"]}}`,
+		},
+		{
+			"whole document",
+			`{"sources": [{"path": "$"}, {"value": {"user": {"id": 1}}}], "targetPath": "$"}`,
+			`{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false},"tags":["a","b"]}},"user":{"id":1,"settings":{"alerts":{"sms":true},"tags":["c"]}}}`,
+		},
+		{
+			"missing sources",
+			`{"sources": [{"path": "missing"}, {"path": "also.missing"}], "targetPath": "merged"}`,
+			jsonIn,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Merge, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(tc.jsonOut))
+			if !areEqual {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestMergeDoesNotModifySpec(t *testing.T) {
+	spec := `{"sources": [{"value": {"tags": ["a"]}}, {"path": "doc"}], "targetPath": "doc", "arrays": "concat"}`
+	jsonIn := `{"doc":{"tags":["b"]}}`
+	jsonOut := `{"doc":{"tags":["a","b"]}}`
+
+	cfg := getConfig(spec, false)
+	for i := 0; i < 2; i++ {
+		kazaamOut, _ := getTransformTestWrapper(Merge, cfg, jsonIn)
+		areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+		if !areEqual {
+			t.Error("Transformed data does not match expectation.")
+			t.Log("Expected:   ", jsonOut)
+			t.Log("Actual:     ", string(kazaamOut))
+			t.FailNow()
+		}
+	}
+}
+
+func TestMergeWithRequire(t *testing.T) {
+	spec := `{"sources": [{"path": "rating.example"}, {"path": "rating.missing"}], "targetPath": "rating.example"}`
+
+	cfg := getConfig(spec, true)
+	_, err := getTransformTestWrapper(Merge, cfg, testJSONInput)
+
+	if err == nil {
+		t.Error("Transform path does not exist in message and should throw an error")
+		t.FailNow()
+	}
+}
+
+func TestMergeInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"targetPath": "a"}`,
+		`{"sources": [{"path": "a"}]}`,
+		`{"sources": ["a"], "targetPath": "a"}`,
+		`{"sources": [{"other": "a"}], "targetPath": "a"}`,
+		`{"sources": [{"path": "a"}], "targetPath": "a", "strategy": "deepest"}`,
+		`{"sources": [{"path": "a"}], "targetPath": "a", "arrays": "zip"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		_, err := getTransformTestWrapper(Merge, cfg, testJSONInput)
+		if _, ok := err.(SpecError); !ok {
+			t.Error("Should have thrown a SpecError for an invalid merge spec.")
+			t.Log("Spec:       ", spec)
+			t.Log("Error:      ", err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// regex modes
+const (
+	regexModeReplace    = "replace"
+	regexModeExtract    = "extract"
+	regexModeExtractAll = "extractAll"
+	regexModeMatch      = "match"
+)
+
+// regexSpec describes the regular expression applied to the strings at a single path.
+type regexSpec struct {
+	re          *regexp.Regexp
+	mode        string
+	replacement string
+	group       int
+	targetPath  string
+}
+
+// Regex matches, extracts or replaces within string values using Go regular
+// expressions. Patterns are compiled once per spec, see PrepareRegex.
+func Regex(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseRegexSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for k, r := range parsed.(map[string]*regexSpec) {
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			// if the key is missing bail and keep iterating
+			if bytes.Equal(dataForV, []byte("null")) {
+				continue
+			}
+			if dataForV[0] != '"' {
+				if spec.Require {
+					return nil, ParseError(fmt.Sprintf("Warn: Unable to apply regex to non-string value at %s", p.path))
+				}
+				continue
+			}
+			decoded, err := decodeJSON(dataForV)
+			if err != nil {
+				return nil, err
+			}
+			result, matched := r.apply(decoded.(string))
+			if !matched {
+				if spec.Require {
+					return nil, ParseError(fmt.Sprintf("Warn: Regex did not match value at %s", p.path))
+				}
+				continue
+			}
+
+			out, err := encodeJSON(result)
+			if err != nil {
+				return nil, err
+			}
+			target := p.path
+			if r.targetPath != "" {
+				target = fillWildcards(r.targetPath, p.indexes)
+			}
+			data, err = setJSONRaw(data, out, target, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// PrepareRegex parses the regex spec, compiling its patterns, ahead of transforming
+// any data.
+func PrepareRegex(spec *Config) error {
+	return spec.prepareWith(parseRegexSpecs)
+}
+
+func parseRegexSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*regexSpec)
+	for k, v := range *spec.Spec {
+		r, err := newRegexSpec(k, v)
+		if err != nil {
+			return nil, err
+		}
+		specs[k] = r
+	}
+	return specs, nil
+}
+
+func newRegexSpec(key string, v interface{}) (*regexSpec, error) {
+	regexMap, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", key))
+	}
+	pattern, ok := regexMap["pattern"].(string)
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"pattern\" for key: %s", key))
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to compile regex for key %s: %v", key, err))
+	}
+	r := &regexSpec{re: re, mode: regexModeReplace}
+
+	if mode, ok := regexMap["mode"]; ok {
+		r.mode, _ = mode.(string)
+	}
+	switch r.mode {
+	case regexModeReplace:
+		if r.replacement, ok = regexMap["replacement"].(string); !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"replacement\" for key: %s", key))
+		}
+	case regexModeExtract, regexModeExtractAll:
+		// default to the first capture group, or the whole match without groups
+		if re.NumSubexp() > 0 {
+			r.group = 1
+		}
+		if group, ok := regexMap["group"]; ok {
+			if r.group, err = regexGroupIndex(re, group); err != nil {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %v for key: %s", err, key))
+			}
+		}
+	case regexModeMatch:
+	default:
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown mode %v for key: %s", regexMap["mode"], key))
+	}
+
+	if targetPath, ok := regexMap["targetPath"]; ok {
+		if r.targetPath, ok = targetPath.(string); !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"targetPath\" must be a string for key: %s", key))
+		}
+	}
+	return r, nil
+}
+
+// regexGroupIndex resolves a capture group given by number or by name.
+func regexGroupIndex(re *regexp.Regexp, group interface{}) (int, error) {
+	switch groupTyped := group.(type) {
+	case float64:
+		index := int(groupTyped)
+		if float64(index) == groupTyped && index >= 0 && index <= re.NumSubexp() {
+			return index, nil
+		}
+	case string:
+		for i, name := range re.SubexpNames() {
+			if name != "" && name == groupTyped {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown capture group %v", group)
+}
+
+// apply runs the regex against value, returning the value to set and whether the
+// pattern matched. Replacing and listing all matches always succeed.
+func (r *regexSpec) apply(value string) (interface{}, bool) {
+	switch r.mode {
+	case regexModeReplace:
+		return r.re.ReplaceAllString(value, r.replacement), true
+	case regexModeExtract:
+		match := r.re.FindStringSubmatchIndex(value)
+		if match == nil || match[2*r.group] < 0 {
+			return nil, false
+		}
+		return value[match[2*r.group]:match[2*r.group+1]], true
+	case regexModeExtractAll:
+		results := []string{}
+		for _, match := range r.re.FindAllStringSubmatchIndex(value, -1) {
+			if match[2*r.group] >= 0 {
+				results = append(results, value[match[2*r.group]:match[2*r.group+1]])
+			}
+		}
+		return results, true
+	default:
+		return r.re.MatchString(value), true
+	}
+}
@@ -0,0 +1,129 @@
+package transform
+
+import "testing"
+
+func TestRegex(t *testing.T) {
+	jsonIn := `{"note":"Re: order #12345 and #678","phone":"(555) 123-4567","phones":["555.987.6543","n/a"],"count":5}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"replace",
+			`{"phone": {"pattern": "\\D", "replacement": ""}}`,
+			`{"note":"Re: order #12345 and #678","phone":"5551234567","phones":["555.987.6543","n/a"],"count":5}`,
+		},
+		{
+			"replace with backreferences and wildcard",
+			`{"phones[*]": {"pattern": "^(\\d{3})\\D?(\\d{3})\\D?(\\d{4})$", "mode": "replace", "replacement": "($1) ${2}-$3"}}`,
+			`{"note":"Re: order #12345 and #678","phone":"(555) 123-4567","phones":["(555) 987-6543","n/a"],"count":5}`,
+		},
+		{
+			"extract first group",
+			`{"note": {"pattern": "#(\\d+)", "mode": "extract", "targetPath": "orderId"}}`,
+			`{"note":"Re: order #12345 and #678","phone":"(555) 123-4567","phones":["555.987.6543","n/a"],"count":5,"orderId":"12345"}`,
+		},
+		{
+			"extract named group",
+			`{"note": {"pattern": "#(?P<id>\\d+)", "mode": "extract", "group": "id", "targetPath": "orderId"}}`,
+			`{"note":"Re: order #12345 and #678","phone":"(555) 123-4567","phones":["555.987.6543","n/a"],"count":5,"orderId":"12345"}`,
+		},
+		{
+			"extract without groups",
+			`{"note": {"pattern": "#\\d+", "mode": "extract"}}`,
+			`{"note":"#12345","phone":"(555) 123-4567","phones":["555.987.6543","n/a"],"count":5}`,
+		},
+		{
+			"extract all",
+			`{"note": {"pattern": "#(\\d+)", "mode": "extractAll", "targetPath": "orderIds"}}`,
+			`{"note":"Re: order #12345 and #678","phone":"(555) 123-4567","phones":["555.987.6543","n/a"],"count":5,"orderIds":["12345","678"]}`,
+		},
+		{
+			"match",
+			`{"phones[*]": {"pattern": "^[\\d.]+$", "mode": "match", "targetPath": "valid[*]"}}`,
+			`{"note":"Re: order #12345 and #678","phone":"(555) 123-4567","phones":["555.987.6543","n/a"],"count":5,"valid":[true,false]}`,
+		},
+		{
+			"no match and non-string values are skipped",
+			`{"phone": {"pattern": "#(\\d+)", "mode": "extract"}, "count": {"pattern": "5", "replacement": "6"}}`,
+			jsonIn,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Regex, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(tc.jsonOut))
+			if !areEqual {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestRegexPrepared(t *testing.T) {
+	spec := `{"phone": {"pattern": "\\D", "replacement": ""}}`
+	jsonIn := `{"phone":"555-1234"}`
+	jsonOut := `{"phone":"5551234"}`
+
+	cfg := getConfig(spec, false)
+	if err := PrepareRegex(&cfg); err != nil {
+		t.Fatalf("unexpected error preparing spec: %v", err)
+	}
+	// the compiled pattern is used, not the one in the spec
+	(*cfg.Spec)["phone"] = "invalid"
+	kazaamOut, err := getTransformTestWrapper(Regex, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestRegexRequire(t *testing.T) {
+	testCases := []string{
+		`{"missing": {"pattern": "a", "replacement": "b"}}`,
+		`{"rating.primary.value": {"pattern": "a", "replacement": "b"}}`,
+		`{"note": {"pattern": "#(\\d+)", "mode": "extract"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, true)
+		_, err := getTransformTestWrapper(Regex, cfg, `{"note":"none","rating":{"primary":{"value":3}}}`)
+		if err == nil {
+			t.Error("Should have thrown an error with require.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestRegexInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"note": "a"}`,
+		`{"note": {"replacement": "b"}}`,
+		`{"note": {"pattern": "(", "replacement": "b"}}`,
+		`{"note": {"pattern": "a"}}`,
+		`{"note": {"pattern": "a", "mode": "find"}}`,
+		`{"note": {"pattern": "(a)", "mode": "extract", "group": 2}}`,
+		`{"note": {"pattern": "(a)", "mode": "extract", "group": "name"}}`,
+		`{"note": {"pattern": "a", "mode": "match", "targetPath": 1}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareRegex(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid regex spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/qntfy/jsonparser"
+)
+
+// renameRule is a regex-based key rename.
+type renameRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// renameSpec describes how the keys of a subtree are rewritten.
+type renameSpec struct {
+	path    string
+	mapping map[string]string
+	rules   []renameRule
+	style   func(string) string
+	depth   int
+	include []string
+	exclude []string
+	keySep  string
+}
+
+// RenameKeys recursively rewrites the object keys of the document, or of the subtree
+// at `path`, using an explicit mapping, regex rules and/or a casing style. A `path`
+// with `[*]` wildcards renames the subtree of every matching element separately.
+func RenameKeys(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseRenameSpec)
+	if err != nil {
+		return nil, err
+	}
+	r := parsed.(*renameSpec)
+
+	if r.path == "$" {
+		return r.walkContainer(data)
+	}
+	paths, err := expandWildcards(data, r.path, spec.Require, spec.KeySeparator)
+	if err != nil {
+		return nil, err
+	}
+	// every subtree is renamed separately, before any of them is set
+	var results []exprResult
+	for _, p := range paths {
+		dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		// a missing or null subtree has no keys, and is not added
+		if string(dataForV) == "null" {
+			continue
+		}
+		renamed, err := r.walkContainer(dataForV)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, exprResult{path: p.path, value: renamed})
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareRenameKeys parses the renameKeys spec, compiling its regex rules, ahead of
+// transforming any data.
+func PrepareRenameKeys(spec *Config) error {
+	return spec.prepareWith(parseRenameSpec)
+}
+
+func parseRenameSpec(spec *Config) (interface{}, error) {
+	r := &renameSpec{path: "$", keySep: spec.KeySeparator}
+	if path, ok := (*spec.Spec)["path"]; ok {
+		if r.path, ok = path.(string); !ok {
+			return nil, SpecError("Warn: Invalid spec. \"path\" must be a string")
+		}
+	}
+
+	if mapping, ok := (*spec.Spec)["mapping"]; ok {
+		mappingMap, ok := mapping.(map[string]interface{})
+		if !ok {
+			return nil, SpecError("Warn: Invalid spec. \"mapping\" must be an object")
+		}
+		r.mapping = make(map[string]string)
+		for old, newInterface := range mappingMap {
+			newKey, ok := newInterface.(string)
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. New name for key %s must be a string", old))
+			}
+			r.mapping[old] = newKey
+		}
+	}
+
+	if rules, ok := (*spec.Spec)["regex"]; ok {
+		ruleList, ok := rules.([]interface{})
+		if !ok {
+			return nil, SpecError("Warn: Invalid spec. \"regex\" must be a list")
+		}
+		for _, ruleInterface := range ruleList {
+			rule, _ := ruleInterface.(map[string]interface{})
+			pattern, patternOk := rule["pattern"].(string)
+			replacement, replacementOk := rule["replacement"].(string)
+			if !patternOk || !replacementOk {
+				return nil, SpecError(fmt.Sprintf("Error processing %v: must have pattern and replacement specified", ruleInterface))
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to compile regex %s: %v", pattern, err))
+			}
+			r.rules = append(r.rules, renameRule{re: re, replacement: replacement})
+		}
+	}
+
+	if style, ok := (*spec.Spec)["case"]; ok {
+		styleStr, _ := style.(string)
+		if r.style, ok = caseStyles[styleStr]; !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown case: %v", style))
+		}
+	}
+
+	if r.mapping == nil && r.rules == nil && r.style == nil {
+		return nil, SpecError("Warn: Invalid spec. One of \"mapping\", \"regex\" or \"case\" is required")
+	}
+
+	if depth, ok := (*spec.Spec)["depth"]; ok {
+		depthFloat, ok := depth.(float64)
+		if !ok || depthFloat < 0 || depthFloat != float64(int(depthFloat)) {
+			return nil, SpecError("Warn: Invalid spec. \"depth\" must be a non-negative integer")
+		}
+		r.depth = int(depthFloat)
+	}
+
+	var err error
+	if r.include, err = specStringList(spec, "include"); err != nil {
+		return nil, err
+	}
+	if r.exclude, err = specStringList(spec, "exclude"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// specStringList reads an optional list of strings from the spec.
+func specStringList(spec *Config, field string) ([]string, error) {
+	listInterface, ok := (*spec.Spec)[field]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := listInterface.([]interface{})
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a list of strings", field))
+	}
+	var result []string
+	for _, item := range list {
+		itemStr, ok := item.(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a list of strings", field))
+		}
+		result = append(result, itemStr)
+	}
+	return result, nil
+}
+
+// walkContainer renames the keys of an object or array; any other value has no keys
+// and is returned unchanged.
+func (r *renameSpec) walkContainer(value []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 {
+		return value, nil
+	}
+	switch trimmed[0] {
+	case '{':
+		return r.walk(trimmed, jsonparser.Object, "", 1)
+	case '[':
+		return r.walk(trimmed, jsonparser.Array, "", 1)
+	default:
+		return value, nil
+	}
+}
+
+// walk rebuilds value with renamed keys. path is the original key path of value
+// relative to the root of the subtree, with array elements written as `[*]`, and
+// level is the object nesting level of the keys of value. Distinct keys of an object
+// renamed to the same key are an error, rather than duplicate keys in the output.
+func (r *renameSpec) walk(value []byte, dataType jsonparser.ValueType, path string, level int) ([]byte, error) {
+	switch dataType {
+	case jsonparser.Object:
+		var buffer bytes.Buffer
+		buffer.WriteByte('{')
+		// originals maps each output key to the original key renamed to it
+		originals := make(map[string]string)
+		err := jsonparser.ObjectEach(value, func(key []byte, child []byte, childType jsonparser.ValueType, offset int) error {
+			keyStr, err := jsonparser.ParseString(key)
+			if err != nil {
+				return err
+			}
+			childPath := keyStr
+			if path != "" {
+				childPath = strings.Join([]string{path, keyStr}, r.keySep)
+			}
+			original := keyStr
+			if r.depth == 0 || level <= r.depth {
+				if r.selected(childPath) {
+					keyStr = r.rename(keyStr)
+				}
+			}
+			if other, ok := originals[keyStr]; ok && other != original {
+				return fmt.Errorf("keys %q and %q are both renamed to %q", other, original, keyStr)
+			}
+			originals[keyStr] = original
+			renamedChild, err := r.walk(child, childType, childPath, level+1)
+			if err != nil {
+				return err
+			}
+			if buffer.Len() > 1 {
+				buffer.WriteByte(',')
+			}
+			encodedKey, err := encodeJSON(keyStr)
+			if err != nil {
+				return err
+			}
+			buffer.Write(encodedKey)
+			buffer.WriteByte(':')
+			buffer.Write(renamedChild)
+			return nil
+		})
+		if err != nil {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to rename keys: %v", err))
+		}
+		buffer.WriteByte('}')
+		return buffer.Bytes(), nil
+	case jsonparser.Array:
+		var buffer bytes.Buffer
+		var walkErr error
+		buffer.WriteByte('[')
+		_, err := jsonparser.ArrayEach(value, func(element []byte, elementType jsonparser.ValueType, offset int, err error) {
+			if walkErr != nil {
+				return
+			}
+			var renamedElement []byte
+			renamedElement, walkErr = r.walk(element, elementType, path+"[*]", level)
+			if buffer.Len() > 1 {
+				buffer.WriteByte(',')
+			}
+			buffer.Write(renamedElement)
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+		if err != nil {
+			return nil, ParseError(fmt.Sprintf("Warn: Unable to rename keys: %v", err))
+		}
+		buffer.WriteByte(']')
+		return buffer.Bytes(), nil
+	default:
+		return HandleUnquotedStrings(value, dataType), nil
+	}
+}
+
+// selected reports whether the key at path may be renamed given the include and
+// exclude lists. A listed path covers the key at that path and all keys beneath it.
+func (r *renameSpec) selected(path string) bool {
+	for _, excluded := range r.exclude {
+		if r.pathCovers(excluded, path) {
+			return false
+		}
+	}
+	if r.include == nil {
+		return true
+	}
+	for _, included := range r.include {
+		if r.pathCovers(included, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *renameSpec) pathCovers(parent, path string) bool {
+	return path == parent ||
+		strings.HasPrefix(path, parent+r.keySep) ||
+		strings.HasPrefix(path, parent+"[")
+}
+
+// rename applies the explicit mapping or, for unmapped keys, the regex rules and
+// the casing style, in that order.
+func (r *renameSpec) rename(key string) string {
+	if newKey, ok := r.mapping[key]; ok {
+		return newKey
+	}
+	for _, rule := range r.rules {
+		key = rule.re.ReplaceAllString(key, rule.replacement)
+	}
+	if r.style != nil {
+		key = r.style(key)
+	}
+	return key
+}
@@ -0,0 +1,147 @@
+package transform
+
+import "testing"
+
+func TestRenameKeys(t *testing.T) {
+	jsonIn := `{"user_id":12345678901234567890,"first_name":"Jane","home_address":{"street_name":"Main \"St\"","zip_code":"02134"},"line_items":[{"item_sku":"a"},{"item_sku":"b"}],"meta_data":{"raw_key":1}}`
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonOut string
+	}{
+		{
+			"case style",
+			`{"case": "camel"}`,
+			`{"userId":12345678901234567890,"firstName":"Jane","homeAddress":{"streetName":"Main \"St\"","zipCode":"02134"},"lineItems":[{"itemSku":"a"},{"itemSku":"b"}],"metaData":{"rawKey":1}}`,
+		},
+		{
+			"mapping takes precedence over case",
+			`{"case": "camel", "mapping": {"user_id": "id", "zip_code": "postalCode"}}`,
+			`{"id":12345678901234567890,"firstName":"Jane","homeAddress":{"streetName":"Main \"St\"","postalCode":"02134"},"lineItems":[{"itemSku":"a"},{"itemSku":"b"}],"metaData":{"rawKey":1}}`,
+		},
+		{
+			"regex rules",
+			`{"regex": [{"pattern": "^(item|home)_", "replacement": ""}, {"pattern": "_name$", "replacement": "Name"}]}`,
+			`{"user_id":12345678901234567890,"firstName":"Jane","address":{"streetName":"Main \"St\"","zip_code":"02134"},"line_items":[{"sku":"a"},{"sku":"b"}],"meta_data":{"raw_key":1}}`,
+		},
+		{
+			"depth limit",
+			`{"case": "kebab", "depth": 1}`,
+			`{"user-id":12345678901234567890,"first-name":"Jane","home-address":{"street_name":"Main \"St\"","zip_code":"02134"},"line-items":[{"item_sku":"a"},{"item_sku":"b"}],"meta-data":{"raw_key":1}}`,
+		},
+		{
+			"exclude",
+			`{"case": "camel", "exclude": ["meta_data", "line_items[*].item_sku"]}`,
+			`{"userId":12345678901234567890,"firstName":"Jane","homeAddress":{"streetName":"Main \"St\"","zipCode":"02134"},"lineItems":[{"item_sku":"a"},{"item_sku":"b"}],"meta_data":{"raw_key":1}}`,
+		},
+		{
+			"include",
+			`{"case": "camel", "include": ["home_address"]}`,
+			`{"user_id":12345678901234567890,"first_name":"Jane","homeAddress":{"streetName":"Main \"St\"","zipCode":"02134"},"line_items":[{"item_sku":"a"},{"item_sku":"b"}],"meta_data":{"raw_key":1}}`,
+		},
+		{
+			"sub-path",
+			`{"case": "upper", "path": "line_items"}`,
+			`{"user_id":12345678901234567890,"first_name":"Jane","home_address":{"street_name":"Main \"St\"","zip_code":"02134"},"line_items":[{"ITEM_SKU":"a"},{"ITEM_SKU":"b"}],"meta_data":{"raw_key":1}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(RenameKeys, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// compare as strings: key order and number precision must be preserved
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestRenameKeysRequire(t *testing.T) {
+	cfg := getConfig(`{"case": "camel", "path": "missing"}`, true)
+	_, err := getTransformTestWrapper(RenameKeys, cfg, testJSONInput)
+
+	if err == nil {
+		t.Error("Transform path does not exist in message and should throw an error")
+		t.FailNow()
+	}
+}
+
+func TestRenameKeysMissingPath(t *testing.T) {
+	for _, jsonIn := range []string{`{"a":1}`, `{"a":1,"missing":null}`} {
+		cfg := getConfig(`{"case": "camel", "path": "missing"}`, false)
+		kazaamOut, err := getTransformTestWrapper(RenameKeys, cfg, jsonIn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(kazaamOut) != jsonIn {
+			t.Error("Transformed data does not match expectation.")
+			t.Log("Expected:   ", jsonIn)
+			t.Log("Actual:     ", string(kazaamOut))
+		}
+	}
+}
+
+func TestRenameKeysWildcardPath(t *testing.T) {
+	cfg := getConfig(`{"case": "camel", "path": "items[*].attrs"}`, false)
+	jsonIn := `{"items":[{"attrs":{"a_b":1},"x_y":0},{"attrs":{"c_d":2}},{"other":3}]}`
+	jsonOut := `{"items":[{"attrs":{"aB":1},"x_y":0},{"attrs":{"cD":2}},{"other":3}]}`
+	kazaamOut, err := getTransformTestWrapper(RenameKeys, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestRenameKeysCollision(t *testing.T) {
+	testCases := []struct {
+		spec   string
+		jsonIn string
+	}{
+		{`{"case": "camel"}`, `{"user_id":1,"userId":2}`},
+		{`{"mapping": {"a": "c", "b": "c"}}`, `{"x":{"a":1,"b":2}}`},
+		{`{"regex": [{"pattern": "_.*$", "replacement": ""}]}`, `{"items":[{"id_a":1,"id_b":2}]}`},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, false)
+		_, err := getTransformTestWrapper(RenameKeys, cfg, tc.jsonIn)
+		if _, ok := err.(ParseError); !ok {
+			t.Error("Should have thrown a ParseError for keys renamed to the same key.")
+			t.Log("Spec:       ", tc.spec)
+			t.Log("Actual:     ", err)
+		}
+	}
+}
+
+func TestRenameKeysInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{}`,
+		`{"case": "sponge"}`,
+		`{"mapping": ["a"]}`,
+		`{"mapping": {"a": 1}}`,
+		`{"regex": [{"pattern": "("}]}`,
+		`{"regex": [{"pattern": "(", "replacement": ""}]}`,
+		`{"case": "camel", "depth": -1}`,
+		`{"case": "camel", "exclude": "a"}`,
+		`{"case": "camel", "path": 1}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareRenameKeys(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid renameKeys spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package transform
 
 import (
+	"bytes"
 	"fmt"
 )
 
+// shift modes control how the values of several source paths are combined when
+// they are shifted into the same target key.
+const (
+	shiftModeOverwrite = "overwrite"
+	shiftModeAppend    = "append"
+	shiftModeMerge     = "merge"
+)
+
 // Shift moves values from one provided json path to another in raw []byte.
 func Shift(spec *Config, data []byte) ([]byte, error) {
 	var outData []byte
@@ -14,8 +23,29 @@ func Shift(spec *Config, data []byte) ([]byte, error) {
 	}
 	for k, v := range *spec.Spec {
 		array := true
+		mode := shiftModeOverwrite
 		var keyList []string
 
+		// the object form carries the source paths under `paths` along with the mode
+		// used to combine them
+		if vMap, ok := v.(map[string]interface{}); ok {
+			v, ok = vMap["paths"]
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"paths\" for key: %s", k))
+			}
+			if modeInterface, ok := vMap["mode"]; ok {
+				mode, ok = modeInterface.(string)
+				if !ok {
+					return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"mode\" must be a string for key: %s", k))
+				}
+			}
+			switch mode {
+			case shiftModeOverwrite, shiftModeAppend, shiftModeMerge:
+			default:
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown mode %q for key: %s", mode, k))
+			}
+		}
+
 		// check if `v` is a string or list and build a list of keys to evaluate
 		switch v.(type) {
 		case string:
@@ -33,6 +63,10 @@ func Shift(spec *Config, data []byte) ([]byte, error) {
 			return nil, ParseError(fmt.Sprintf("Warn: Unknown type in message for key: %s", k))
 		}
 
+		// values collected for the append and merge modes, which are combined and
+		// set once all the keys have been evaluated
+		var collected [][]byte
+
 		// iterate over keys to evaluate
 		// Note: this could be sped up significantly (especially for large shift transforms)
 		// by using `jsonparser.EachKey()` to iterate through data once and pick up all the
@@ -54,6 +88,14 @@ func Shift(spec *Config, data []byte) ([]byte, error) {
 				}
 			}
 
+			if mode != shiftModeOverwrite {
+				// missing values are skipped rather than accumulated as nulls
+				if !bytes.Equal(dataForV, []byte("null")) {
+					collected = append(collected, dataForV)
+				}
+				continue
+			}
+
 			// if array flag set, encapsulate data
 			if array {
 				// bookend() is destructive to underlying slice, need to copy.
@@ -62,14 +104,46 @@ func Shift(spec *Config, data []byte) ([]byte, error) {
 				copy(tmp, dataForV)
 				dataForV = bookend(tmp, '[', ']')
 			}
-			// Note: following pattern from current Shift() - if multiple elements are included in an array,
-			// they will each successively overwrite each other and only the last element will be included
-			// in the transformed data.
+			// Note: in overwrite mode, if multiple elements are included in an array,
+			// they will each successively overwrite each other and only the last element
+			// will be included in the transformed data.
 			outData, err = setJSONRaw(outData, dataForV, k, spec.KeySeparator)
 			if err != nil {
 				return nil, err
 			}
 		}
+
+		var err error
+		switch mode {
+		case shiftModeAppend:
+			outData, err = setJSONRaw(outData, bookend(bytes.Join(collected, []byte(",")), '[', ']'), k, spec.KeySeparator)
+		case shiftModeMerge:
+			outData, err = shiftMerge(outData, collected, k, spec.KeySeparator)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 	return outData, nil
 }
+
+// shiftMerge deep-merges the collected values, in order, and sets the result at key.
+// The key is left unset when no value was collected.
+func shiftMerge(data []byte, values [][]byte, key, keySeparator string) ([]byte, error) {
+	if len(values) == 0 {
+		return data, nil
+	}
+	var merged interface{}
+	for _, value := range values {
+		decoded, err := decodeJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		merged = deepMerge(merged, decoded)
+	}
+	out, err := encodeJSON(merged)
+	if err != nil {
+		return nil, err
+	}
+	return setJSONRaw(data, out, key, keySeparator)
+}
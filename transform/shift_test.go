@@ -234,3 +234,107 @@ func TestShiftWithEndArrayAccess(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestShiftAppendMode(t *testing.T) {
+	spec := `{"phones": {"paths": ["billing.phone", "shipping.phone", "other.phone"], "mode": "append"}}`
+	jsonIn := `{"billing":{"phone":"555-1234"},"shipping":{"phone":"555-5678"}}`
+	jsonOut := `{"phones":["555-1234","555-5678"]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Shift, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error on transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestShiftMergeMode(t *testing.T) {
+	spec := `{"settings": {"paths": ["defaults.settings", "user.settings"], "mode": "merge"}}`
+	jsonIn := `{"defaults":{"settings":{"theme":"light","alerts":{"email":true,"sms":false}}},"user":{"settings":{"alerts":{"sms":true},"id":12345678901234567890}}}`
+	jsonOut := `{"settings":{"theme":"light","alerts":{"email":true,"sms":true},"id":12345678901234567890}}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Shift, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error on transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestShiftMergeModeMissingPaths(t *testing.T) {
+	spec := `{"settings": {"paths": ["defaults.settings", "user.settings"], "mode": "merge"}, "id": "user.id"}`
+	jsonIn := `{"defaults":{},"user":{"settings":null,"id":1}}`
+	jsonOut := `{"id":1}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Shift, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error on transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestShiftOverwriteModeObjectForm(t *testing.T) {
+	spec := `{"phone": {"paths": "shipping.phone"}, "phones": {"paths": ["billing.phone", "shipping.phone"], "mode": "overwrite"}}`
+	jsonIn := `{"billing":{"phone":"555-1234"},"shipping":{"phone":"555-5678"}}`
+	jsonOut := `{"phone":"555-5678","phones":["555-5678"]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, _ := getTransformTestWrapper(Shift, cfg, jsonIn)
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestShiftInvalidMode(t *testing.T) {
+	testCases := []string{
+		`{"phones": {"paths": ["billing.phone"], "mode": "concat"}}`,
+		`{"phones": {"paths": ["billing.phone"], "mode": 1}}`,
+		`{"phones": {"mode": "append"}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		_, err := getTransformTestWrapper(Shift, cfg, testJSONInput)
+		if _, ok := err.(SpecError); !ok {
+			t.Error("Should have thrown a SpecError for an invalid shift mode.")
+			t.Log("Spec:       ", spec)
+			t.Log("Error:      ", err)
+		}
+	}
+}
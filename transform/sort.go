@@ -0,0 +1,259 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sort value types
+const (
+	sortTypeAuto   = "auto"
+	sortTypeNumber = "number"
+	sortTypeString = "string"
+	sortTypeTime   = "time"
+)
+
+// sortSpec describes the array to sort and the keys to sort it by.
+type sortSpec struct {
+	path       string
+	targetPath string
+	keys       []sortKey
+}
+
+// sortKey is a value of the elements to sort by, in order of precedence.
+type sortKey struct {
+	path   string
+	desc   bool
+	kind   string
+	format string
+}
+
+// Sort orders the elements of an array by their value or by one or more sub-paths of
+// the elements. The sort is stable, so elements that compare equal keep their order.
+func Sort(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseSortSpec)
+	if err != nil {
+		return nil, err
+	}
+	s := parsed.(*sortSpec)
+	return transformArrays(spec, data, s.path, s.targetPath, func(array []byte) ([]byte, error) {
+		return s.sort(array, spec.KeySeparator)
+	})
+}
+
+// PrepareSort parses the sort spec ahead of transforming any data.
+func PrepareSort(spec *Config) error {
+	return spec.prepareWith(parseSortSpec)
+}
+
+func parseSortSpec(spec *Config) (interface{}, error) {
+	s := &sortSpec{}
+	var ok bool
+	if s.path, ok = (*spec.Spec)["path"].(string); !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"path\"")
+	}
+	if targetPath, ok := (*spec.Spec)["targetPath"]; ok {
+		if s.targetPath, ok = targetPath.(string); !ok {
+			return nil, SpecError("Warn: Invalid spec. \"targetPath\" must be a string")
+		}
+	}
+
+	// order, type and format set the defaults for every key
+	defaults, err := newSortKey(*spec.Spec, sortKey{path: "$", kind: sortTypeAuto, format: time.RFC3339})
+	if err != nil {
+		return nil, err
+	}
+	by, ok := (*spec.Spec)["by"]
+	if !ok {
+		s.keys = []sortKey{defaults}
+		return s, nil
+	}
+	byList, ok := by.([]interface{})
+	if !ok {
+		byList = []interface{}{by}
+	}
+	if len(byList) == 0 {
+		return nil, SpecError("Warn: Invalid spec. \"by\" must not be empty")
+	}
+	for _, item := range byList {
+		key := defaults
+		switch itemTyped := item.(type) {
+		case string:
+			key.path = itemTyped
+		case map[string]interface{}:
+			if key.path, ok = itemTyped["path"].(string); !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"path\" of sort key %v", item))
+			}
+			if key, err = newSortKey(itemTyped, key); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Sort key must be a path or an object: %v", item))
+		}
+		s.keys = append(s.keys, key)
+	}
+	return s, nil
+}
+
+// newSortKey overrides the order, type and format of key with those set in keyMap.
+func newSortKey(keyMap map[string]interface{}, key sortKey) (sortKey, error) {
+	if order, ok := keyMap["order"]; ok {
+		switch order {
+		case "asc":
+			key.desc = false
+		case "desc":
+			key.desc = true
+		default:
+			return key, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown sort order: %v", order))
+		}
+	}
+	if kind, ok := keyMap["type"]; ok {
+		switch kind {
+		case sortTypeAuto, sortTypeNumber, sortTypeString, sortTypeTime:
+			key.kind = kind.(string)
+		default:
+			return key, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown sort type: %v", kind))
+		}
+	}
+	if format, ok := keyMap["format"]; ok {
+		if key.format, ok = format.(string); !ok {
+			return key, SpecError("Warn: Invalid spec. \"format\" must be a string")
+		}
+	}
+	return key, nil
+}
+
+// sort returns the raw array with its elements sorted.
+func (s *sortSpec) sort(array []byte, keySeparator string) ([]byte, error) {
+	elements, err := arrayElements(array)
+	if err != nil {
+		return nil, err
+	}
+	// extract the values to compare once per element
+	values := make([][]interface{}, len(elements))
+	for i, element := range elements {
+		values[i] = make([]interface{}, len(s.keys))
+		for j, key := range s.keys {
+			if values[i][j], err = key.value(element, keySeparator); err != nil {
+				return nil, err
+			}
+		}
+	}
+	order := make([]int, len(elements))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		for j, key := range s.keys {
+			if cmp := key.compare(values[order[a]][j], values[order[b]][j]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	sorted := make([][]byte, len(elements))
+	for i, index := range order {
+		sorted[i] = elements[index]
+	}
+	return joinArray(sorted), nil
+}
+
+// value extracts the value of the key from an element and converts it to the type
+// of the key. Values that cannot be converted are nil.
+func (k *sortKey) value(element []byte, keySeparator string) (interface{}, error) {
+	raw := element
+	if k.path != "$" {
+		var err error
+		if raw, err = getJSONRaw(element, k.path, false, keySeparator); err != nil {
+			return nil, err
+		}
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+	value := normalizeNumbers(decoded)
+	if value == nil {
+		return nil, nil
+	}
+	switch k.kind {
+	case sortTypeNumber:
+		number, _ := exprToNumber([]interface{}{value})
+		return number, nil
+	case sortTypeString:
+		return exprString(value), nil
+	case sortTypeTime:
+		if t, ok := parseTime(value, k.format); ok {
+			return t, nil
+		}
+		return nil, nil
+	}
+	return value, nil
+}
+
+// compare orders two values of the key. Null values, including values that could not
+// be converted to the type of the key, sort last in either order.
+func (k *sortKey) compare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	cmp := compareSortValues(a, b)
+	if k.desc {
+		return -cmp
+	}
+	return cmp
+}
+
+// compareSortValues orders values of the same type naturally, and values of
+// different types as booleans, then numbers, then strings, then anything else.
+func compareSortValues(a, b interface{}) int {
+	rankA, rankB := sortRank(a), sortRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+	switch aTyped := a.(type) {
+	case bool:
+		bTyped := b.(bool)
+		switch {
+		case aTyped == bTyped:
+			return 0
+		case bTyped:
+			return -1
+		}
+		return 1
+	case int64, float64:
+		return compareNumbers(a, b)
+	case string:
+		return strings.Compare(aTyped, b.(string))
+	case time.Time:
+		bTyped := b.(time.Time)
+		switch {
+		case aTyped.Before(bTyped):
+			return -1
+		case aTyped.After(bTyped):
+			return 1
+		}
+	}
+	return 0
+}
+
+func sortRank(v interface{}) int {
+	switch v.(type) {
+	case bool:
+		return 0
+	case int64, float64:
+		return 1
+	case string:
+		return 2
+	case time.Time:
+		return 3
+	}
+	return 4
+}
@@ -0,0 +1,131 @@
+package transform
+
+import "testing"
+
+func TestSort(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonIn  string
+		jsonOut string
+	}{
+		{
+			"strings by value",
+			`{"path": "tags"}`,
+			`{"tags":["pear","apple","fig"]}`,
+			`{"tags":["apple","fig","pear"]}`,
+		},
+		{
+			"numbers descending with nulls last",
+			`{"path": "scores", "order": "desc"}`,
+			`{"scores":[3,null,10,2.5]}`,
+			`{"scores":[10,3,2.5,null]}`,
+		},
+		{
+			"numeric strings as numbers",
+			`{"path": "ids", "type": "number"}`,
+			`{"ids":["10","9","100","x"]}`,
+			`{"ids":["9","10","100","x"]}`,
+		},
+		{
+			"numbers as strings",
+			`{"path": "ids", "type": "string"}`,
+			`{"ids":[10,9,100]}`,
+			`{"ids":[10,100,9]}`,
+		},
+		{
+			"by multiple sub-paths, stable",
+			`{"path": "people", "by": ["last", {"path": "age", "order": "desc"}]}`,
+			`{"people":[{"first":"a","last":"Smith","age":30},{"first":"b","last":"Jones","age":40},{"first":"c","last":"Smith","age":50},{"first":"d","last":"Smith","age":30}]}`,
+			`{"people":[{"first":"b","last":"Jones","age":40},{"first":"c","last":"Smith","age":50},{"first":"a","last":"Smith","age":30},{"first":"d","last":"Smith","age":30}]}`,
+		},
+		{
+			"by time across offsets",
+			`{"path": "events", "by": "ts", "type": "time"}`,
+			`{"events":[{"ts":"2020-01-01T10:00:00Z"},{"ts":"2020-01-01T11:00:00+02:00"},{"ts":"bad"},{"ts":"2019-12-31T23:59:59.5Z"}]}`,
+			`{"events":[{"ts":"2019-12-31T23:59:59.5Z"},{"ts":"2020-01-01T11:00:00+02:00"},{"ts":"2020-01-01T10:00:00Z"},{"ts":"bad"}]}`,
+		},
+		{
+			"by time with layout and unix",
+			`{"path": "events", "by": [{"path": "day", "type": "time", "format": "01/02/2006"}, {"path": "at", "type": "time", "format": "$unix"}]}`,
+			`{"events":[{"day":"02/01/2020","at":5},{"day":"01/15/2020","at":"7"},{"day":"01/15/2020","at":6.5}]}`,
+			`{"events":[{"day":"01/15/2020","at":6.5},{"day":"01/15/2020","at":"7"},{"day":"02/01/2020","at":5}]}`,
+		},
+		{
+			"mixed types",
+			`{"path": "mixed"}`,
+			`{"mixed":["b",2,{"a":1},true,null,1,"a",false]}`,
+			`{"mixed":[false,true,1,2,"a","b",{"a":1},null]}`,
+		},
+		{
+			"nested arrays under wildcards to target path",
+			`{"path": "groups[*].members", "targetPath": "groups[*].sorted", "by": "name"}`,
+			`{"groups":[{"members":[{"name":"z"},{"name":"m"}]},{"members":[]}]}`,
+			`{"groups":[{"members":[{"name":"z"},{"name":"m"}],"sorted":[{"name":"m"},{"name":"z"}]},{"members":[],"sorted":[]}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Sort, cfg, tc.jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestSortRootArray(t *testing.T) {
+	cfg := getConfig(`{"path": "$", "by": "n"}`, false)
+	kazaamOut, err := getTransformTestWrapper(Sort, cfg, `[{"n":2},{"n":1}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != `[{"n":1},{"n":2}]` {
+		t.Errorf("got %s", kazaamOut)
+	}
+}
+
+func TestSortRequire(t *testing.T) {
+	testCases := []string{
+		`{"path": "missing"}`,
+		`{"path": "name"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, true)
+		_, err := getTransformTestWrapper(Sort, cfg, `{"name":"a"}`)
+		if err == nil {
+			t.Error("Should have thrown an error with require.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestSortInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"by": "a"}`,
+		`{"path": "a", "targetPath": 1}`,
+		`{"path": "a", "order": "up"}`,
+		`{"path": "a", "type": "date"}`,
+		`{"path": "a", "type": "time", "format": 1}`,
+		`{"path": "a", "by": []}`,
+		`{"path": "a", "by": [1]}`,
+		`{"path": "a", "by": [{"order": "desc"}]}`,
+		`{"path": "a", "by": [{"path": "b", "order": "down"}]}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareSort(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid sort spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
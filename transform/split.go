@@ -0,0 +1,189 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// splitSpec describes how the string at a single path is split.
+type splitSpec struct {
+	delim       string
+	re          *regexp.Regexp
+	limit       int
+	trim        bool
+	omitEmpty   bool
+	targetPath  string
+	targetPaths []string
+}
+
+// Split breaks string values apart on a delimiter or regular expression, setting
+// the parts as an array or assigning them positionally to a list of paths.
+func Split(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseSplitSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for k, s := range parsed.(map[string]*splitSpec) {
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			// if the key is missing bail and keep iterating
+			if bytes.Equal(dataForV, []byte("null")) {
+				continue
+			}
+			if dataForV[0] != '"' {
+				if spec.Require {
+					return nil, ParseError(fmt.Sprintf("Warn: Unable to split non-string value at %s", p.path))
+				}
+				continue
+			}
+			decoded, err := decodeJSON(dataForV)
+			if err != nil {
+				return nil, err
+			}
+			parts := s.split(decoded.(string))
+
+			if s.targetPaths != nil {
+				for i, part := range parts {
+					if i >= len(s.targetPaths) {
+						break
+					}
+					out, err := encodeJSON(part)
+					if err != nil {
+						return nil, err
+					}
+					data, err = setJSONRaw(data, out, fillWildcards(s.targetPaths[i], p.indexes), spec.KeySeparator)
+					if err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+
+			target := p.path
+			if s.targetPath != "" {
+				target = fillWildcards(s.targetPath, p.indexes)
+			}
+			// encode the empty result as [] rather than null
+			if parts == nil {
+				parts = []string{}
+			}
+			out, err := encodeJSON(parts)
+			if err != nil {
+				return nil, err
+			}
+			data, err = setJSONRaw(data, out, target, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// PrepareSplit parses the split spec, compiling its regular expressions, ahead of
+// transforming any data.
+func PrepareSplit(spec *Config) error {
+	return spec.prepareWith(parseSplitSpecs)
+}
+
+func parseSplitSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*splitSpec)
+	for k, v := range *spec.Spec {
+		s, err := newSplitSpec(k, v)
+		if err != nil {
+			return nil, err
+		}
+		specs[k] = s
+	}
+	return specs, nil
+}
+
+func newSplitSpec(key string, v interface{}) (*splitSpec, error) {
+	splitMap, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", key))
+	}
+	s := &splitSpec{limit: -1}
+
+	delim, delimOk := splitMap["delim"].(string)
+	pattern, regexOk := splitMap["regex"].(string)
+	switch {
+	case delimOk == regexOk:
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Exactly one of \"delim\" or \"regex\" is required for key: %s", key))
+	case delimOk:
+		s.delim = delim
+	default:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to compile regex for key %s: %v", key, err))
+		}
+		s.re = re
+	}
+
+	if limit, ok := splitMap["limit"]; ok {
+		limitFloat, ok := limit.(float64)
+		if !ok || limitFloat < 0 || limitFloat != float64(int(limitFloat)) {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"limit\" must be a non-negative integer for key: %s", key))
+		}
+		if limitFloat > 0 {
+			s.limit = int(limitFloat)
+		}
+	}
+	s.trim, _ = splitMap["trim"].(bool)
+	s.omitEmpty, _ = splitMap["omitEmpty"].(bool)
+
+	if targetPath, ok := splitMap["targetPath"]; ok {
+		if s.targetPath, ok = targetPath.(string); !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"targetPath\" must be a string for key: %s", key))
+		}
+	}
+	if targetPaths, ok := splitMap["targetPaths"]; ok {
+		targetList, ok := targetPaths.([]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"targetPaths\" must be a list for key: %s", key))
+		}
+		s.targetPaths = []string{}
+		for _, t := range targetList {
+			target, ok := t.(string)
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Error processing %v: path should be a string", t))
+			}
+			s.targetPaths = append(s.targetPaths, target)
+		}
+	}
+	if s.targetPath != "" && s.targetPaths != nil {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Only one of \"targetPath\" or \"targetPaths\" may be set for key: %s", key))
+	}
+	return s, nil
+}
+
+// split breaks value into its parts, applying trimming and empty-part removal.
+func (s *splitSpec) split(value string) []string {
+	var parts []string
+	if s.re != nil {
+		parts = s.re.Split(value, s.limit)
+	} else {
+		parts = strings.SplitN(value, s.delim, s.limit)
+	}
+
+	var result []string
+	for _, part := range parts {
+		if s.trim {
+			part = strings.TrimSpace(part)
+		}
+		if s.omitEmpty && part == "" {
+			continue
+		}
+		result = append(result, part)
+	}
+	return result
+}
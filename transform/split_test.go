@@ -0,0 +1,127 @@
+package transform
+
+import "testing"
+
+func TestSplit(t *testing.T) {
+	spec := `{"tags": {"delim": ","}}`
+	jsonIn := `{"tags":"a,b,c"}`
+	jsonOut := `{"tags":["a","b","c"]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Split, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error in transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestSplitToTargetPaths(t *testing.T) {
+	spec := `{"name": {"delim": ",", "trim": true, "targetPaths": ["last", "first"]}}`
+	jsonIn := `{"name":"Doe,  Jane "}`
+	jsonOut := `{"name":"Doe,  Jane ","last":"Doe","first":"Jane"}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Split, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error in transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestSplitRegexWithLimitAndWildcard(t *testing.T) {
+	spec := `{"items[*].codes": {"regex": "\\s*[;|]\\s*", "limit": 2, "targetPath": "items[*].codeList"}}`
+	jsonIn := `{"items":[{"codes":"a ; b|c"},{"codes":"d"},{"codes":7}]}`
+	jsonOut := `{"items":[{"codes":"a ; b|c","codeList":["a","b|c"]},{"codes":"d","codeList":["d"]},{"codes":7}]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Split, cfg, jsonIn)
+
+	if err != nil {
+		t.Error("Error in transform.")
+		t.Log("Error: ", err.Error())
+		t.FailNow()
+	}
+
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestSplitUnicodeAndEscapes(t *testing.T) {
+	spec := `{"words": {"delim": "·", "omitEmpty": true}}`
+	jsonIn := `{"words":"café··\"quoted\"·日本"}`
+	jsonOut := `{"words":["café","\"quoted\"","日本"]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, _ := getTransformTestWrapper(Split, cfg, jsonIn)
+	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
+
+	if !areEqual {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+		t.FailNow()
+	}
+}
+
+func TestSplitRequire(t *testing.T) {
+	testCases := []string{
+		`{"missing": {"delim": ","}}`,
+		`{"rating.example": {"delim": ","}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, true)
+		_, err := getTransformTestWrapper(Split, cfg, testJSONInput)
+		if err == nil {
+			t.Error("Should have thrown an error for a missing or non-string value with require.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
+
+func TestSplitInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"tags": ","}`,
+		`{"tags": {}}`,
+		`{"tags": {"delim": ",", "regex": ","}}`,
+		`{"tags": {"regex": "("}}`,
+		`{"tags": {"delim": ",", "limit": -1}}`,
+		`{"tags": {"delim": ",", "targetPaths": "a"}}`,
+		`{"tags": {"delim": ",", "targetPath": "a", "targetPaths": ["b"]}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		_, err := getTransformTestWrapper(Split, cfg, `{"tags":"a,b"}`)
+		if _, ok := err.(SpecError); !ok {
+			t.Error("Should have thrown a SpecError for an invalid split spec.")
+			t.Log("Spec:       ", spec)
+			t.Log("Error:      ", err)
+		}
+	}
+}
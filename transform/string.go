@@ -0,0 +1,304 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stringFuncs builds a string function from its options, validating them at load
+// time.
+var stringFuncs = map[string]func(options map[string]interface{}) (func(string) string, error){
+	"trim":      newTrimFunc(strings.TrimSpace, strings.Trim),
+	"trimLeft":  newTrimFunc(func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) }, strings.TrimLeft),
+	"trimRight": newTrimFunc(func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }, strings.TrimRight),
+	"padLeft":   newPadFunc(true),
+	"padRight":  newPadFunc(false),
+	"substring": newSubstringFunc,
+	"truncate":  newTruncateFunc,
+	"replace":   newReplaceFunc,
+}
+
+// String applies an ordered list of string functions, such as trimming or padding,
+// to the string at each path. Numbers are converted to strings first, and arrays have
+// every string or number element converted. Lengths and positions count characters
+// rather than bytes.
+func String(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseStringSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for k, fns := range parsed.(map[string][]func(string) string) {
+		apply := func(s string) string {
+			for _, fn := range fns {
+				s = fn(s)
+			}
+			return s
+		}
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := decodeJSON(dataForV)
+			if err != nil {
+				return nil, err
+			}
+			switch decodedTyped := normalizeNumbers(decoded).(type) {
+			case string, int64, float64:
+				decoded = apply(exprString(decodedTyped))
+			case []interface{}:
+				for i, item := range decodedTyped {
+					switch item.(type) {
+					case string, int64, float64:
+						decodedTyped[i] = apply(exprString(item))
+					}
+				}
+				decoded = decodedTyped
+			default:
+				// other values are left as-is
+				continue
+			}
+			out, err := encodeJSON(decoded)
+			if err != nil {
+				return nil, err
+			}
+			// skip the write when nothing changed
+			if bytes.Equal(out, dataForV) {
+				continue
+			}
+			data, err = setJSONRaw(data, out, p.path, spec.KeySeparator)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// PrepareString parses the string functions of the spec ahead of transforming any
+// data.
+func PrepareString(spec *Config) error {
+	return spec.prepareWith(parseStringSpecs)
+}
+
+func parseStringSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string][]func(string) string)
+	for k, v := range *spec.Spec {
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		if len(items) == 0 {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. No string functions for key: %s", k))
+		}
+		for _, item := range items {
+			// a function without options may be given by name
+			options, ok := item.(map[string]interface{})
+			if name, isName := item.(string); isName {
+				options, ok = map[string]interface{}{"fn": name}, true
+			}
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. String function must be a name or an object for key: %s", k))
+			}
+			name, _ := options["fn"].(string)
+			newFunc, ok := stringFuncs[name]
+			if !ok {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown string function %v for key: %s", options["fn"], k))
+			}
+			fn, err := newFunc(options)
+			if err != nil {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. String function %s %v for key: %s", name, err, k))
+			}
+			specs[k] = append(specs[k], fn)
+		}
+	}
+	return specs, nil
+}
+
+// anyInt is the minimum of integer options that may be negative.
+const anyInt = -int(^uint(0)>>1) - 1
+
+// stringIntOption returns the integer option name, or def when it is not set.
+func stringIntOption(options map[string]interface{}, name string, def int, min int) (int, error) {
+	v, ok := options[name]
+	if !ok {
+		return def, nil
+	}
+	f, ok := v.(float64)
+	switch {
+	case !ok || f != float64(int(f)):
+		return 0, fmt.Errorf("requires %q to be an integer", name)
+	case int(f) < min:
+		return 0, fmt.Errorf("requires %q to be at least %d", name, min)
+	}
+	return int(f), nil
+}
+
+// stringOption returns the string option name, or def when it is not set.
+func stringOption(options map[string]interface{}, name string, def string) (string, error) {
+	v, ok := options[name]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("requires %q to be a string", name)
+	}
+	return s, nil
+}
+
+// requireOptions checks that the required options are set and no others.
+func requireOptions(options map[string]interface{}, required []string, optional ...string) error {
+	for _, name := range required {
+		if _, ok := options[name]; !ok {
+			return fmt.Errorf("requires %q", name)
+		}
+	}
+	for name := range options {
+		known := name == "fn"
+		for _, option := range append(required, optional...) {
+			known = known || name == option
+		}
+		if !known {
+			return fmt.Errorf("has unknown option %q", name)
+		}
+	}
+	return nil
+}
+
+// newTrimFunc trims whitespace, or the characters of the `chars` option.
+func newTrimFunc(trimSpace func(string) string, trimChars func(string, string) string) func(options map[string]interface{}) (func(string) string, error) {
+	return func(options map[string]interface{}) (func(string) string, error) {
+		if err := requireOptions(options, nil, "chars"); err != nil {
+			return nil, err
+		}
+		if _, ok := options["chars"]; !ok {
+			return trimSpace, nil
+		}
+		chars, err := stringOption(options, "chars", "")
+		if err != nil {
+			return nil, err
+		}
+		return func(s string) string { return trimChars(s, chars) }, nil
+	}
+}
+
+// newPadFunc pads strings to `length` characters with the `char` option, a space by
+// default. Longer strings are left as-is.
+func newPadFunc(left bool) func(options map[string]interface{}) (func(string) string, error) {
+	return func(options map[string]interface{}) (func(string) string, error) {
+		if err := requireOptions(options, []string{"length"}, "char"); err != nil {
+			return nil, err
+		}
+		length, err := stringIntOption(options, "length", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		char, err := stringOption(options, "char", " ")
+		if err != nil {
+			return nil, err
+		}
+		if utf8.RuneCountInString(char) != 1 {
+			return nil, fmt.Errorf("requires \"char\" to be a single character")
+		}
+		return func(s string) string {
+			missing := length - utf8.RuneCountInString(s)
+			if missing <= 0 {
+				return s
+			}
+			if left {
+				return strings.Repeat(char, missing) + s
+			}
+			return s + strings.Repeat(char, missing)
+		}, nil
+	}
+}
+
+// newSubstringFunc keeps `length` characters, or the rest of the string, from
+// `start`. A negative start counts from the end of the string.
+func newSubstringFunc(options map[string]interface{}) (func(string) string, error) {
+	if err := requireOptions(options, nil, "start", "length"); err != nil {
+		return nil, err
+	}
+	start, err := stringIntOption(options, "start", 0, anyInt)
+	if err != nil {
+		return nil, err
+	}
+	length, err := stringIntOption(options, "length", -1, 0)
+	if err != nil {
+		return nil, err
+	}
+	return func(s string) string {
+		runes := []rune(s)
+		from := start
+		if from < 0 {
+			from += len(runes)
+		}
+		from = int(clampIndex(int64(from), len(runes)))
+		to := len(runes)
+		if length >= 0 && length < to-from {
+			to = from + length
+		}
+		return string(runes[from:to])
+	}, nil
+}
+
+// newTruncateFunc shortens strings longer than `length` characters, ending them with
+// the `ellipsis` option so that the result is at most `length` characters long.
+func newTruncateFunc(options map[string]interface{}) (func(string) string, error) {
+	if err := requireOptions(options, []string{"length"}, "ellipsis"); err != nil {
+		return nil, err
+	}
+	length, err := stringIntOption(options, "length", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	ellipsis, err := stringOption(options, "ellipsis", "")
+	if err != nil {
+		return nil, err
+	}
+	ellipsisLength := utf8.RuneCountInString(ellipsis)
+	if ellipsisLength > length {
+		return nil, fmt.Errorf("requires \"ellipsis\" to be at most \"length\" characters long")
+	}
+	return func(s string) string {
+		runes := []rune(s)
+		if len(runes) <= length {
+			return s
+		}
+		return string(runes[:length-ellipsisLength]) + ellipsis
+	}, nil
+}
+
+// newReplaceFunc replaces the occurrences of `old` with `new`, at most `count` times
+// when set.
+func newReplaceFunc(options map[string]interface{}) (func(string) string, error) {
+	if err := requireOptions(options, []string{"old"}, "new", "count"); err != nil {
+		return nil, err
+	}
+	old, err := stringOption(options, "old", "")
+	if err != nil {
+		return nil, err
+	}
+	if old == "" {
+		return nil, fmt.Errorf("requires \"old\" to be non-empty")
+	}
+	replacement, err := stringOption(options, "new", "")
+	if err != nil {
+		return nil, err
+	}
+	count, err := stringIntOption(options, "count", -1, 1)
+	if err != nil {
+		return nil, err
+	}
+	return func(s string) string {
+		return strings.Replace(s, old, replacement, count)
+	}, nil
+}
@@ -0,0 +1,80 @@
+package transform
+
+import "testing"
+
+func TestString(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"trim", `{"s": "trim"}`, `{"s":" \t a b \n"}`, `{"s":"a b"}`},
+		{"trim left and right", `{"a": "trimLeft", "b": "trimRight"}`, `{"a":"  x  ","b":"  x  "}`, `{"a":"x  ","b":"  x"}`},
+		{"trim chars", `{"s": {"fn": "trim", "chars": "-*"}}`, `{"s":"*-a-*"}`, `{"s":"a"}`},
+		{"pad left with zeros", `{"id": {"fn": "padLeft", "length": 8, "char": "0"}}`, `{"id":"1234"}`, `{"id":"00001234"}`},
+		{"numbers are converted to strings", `{"id": {"fn": "padLeft", "length": 6, "char": "0"}}`, `{"id":42}`, `{"id":"000042"}`},
+		{"pad right counts characters", `{"s": {"fn": "padRight", "length": 5, "char": "·"}}`, `{"s":"héé"}`, `{"s":"héé··"}`},
+		{"longer strings are not padded", `{"s": {"fn": "padLeft", "length": 2}}`, `{"s":"abc"}`, `{"s":"abc"}`},
+		{"substring", `{"s": {"fn": "substring", "start": 1, "length": 3}}`, `{"s":"żółwie"}`, `{"s":"ółw"}`},
+		{"substring from the end", `{"s": {"fn": "substring", "start": -3}}`, `{"s":"żółwie"}`, `{"s":"wie"}`},
+		{"truncate", `{"s": {"fn": "truncate", "length": 5}}`, `{"s":"日本語のテキスト"}`, `{"s":"日本語のテ"}`},
+		{"truncate with ellipsis", `{"s": {"fn": "truncate", "length": 6, "ellipsis": "…"}}`, `{"s":"a long description"}`, `{"s":"a lon…"}`},
+		{"short strings are not truncated", `{"s": {"fn": "truncate", "length": 6, "ellipsis": "…"}}`, `{"s":"short"}`, `{"s":"short"}`},
+		{"replace", `{"s": {"fn": "replace", "old": "-", "new": " "}}`, `{"s":"a-b-c"}`, `{"s":"a b c"}`},
+		{"replace count", `{"s": {"fn": "replace", "old": "-", "count": 1}}`, `{"s":"a-b-c"}`, `{"s":"ab-c"}`},
+		{"escapes", `{"s": {"fn": "replace", "old": "\"", "new": "\\"}}`, `{"s":"say \"hi\"!"}`, `{"s":"say \\hi\\!"}`},
+		{"functions apply in order", `{"s": ["trim", {"fn": "truncate", "length": 3}, {"fn": "padLeft", "length": 5, "char": "_"}]}`, `{"s":"  abcdef "}`, `{"s":"__abc"}`},
+		{"arrays", `{"tags": "trim"}`, `{"tags":[" a ",1,true," b"]}`, `{"tags":["a","1",true,"b"]}`},
+		{"wildcards", `{"items[*].sku": {"fn": "padLeft", "length": 3, "char": "0"}}`, `{"items":[{"sku":"7"},{"sku":"42"}]}`, `{"items":[{"sku":"007"},{"sku":"042"}]}`},
+		{"other values are left unchanged", `{"a": "trim", "b": "trim", "c": "trim"}`, `{"a":null,"b":{"x":" y "}}`, `{"a":null,"b":{"x":" y "}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(String, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestStringRequire(t *testing.T) {
+	cfg := getConfig(`{"missing": "trim"}`, true)
+	if _, err := getTransformTestWrapper(String, cfg, `{"s":" a "}`); err == nil {
+		t.Error("Should have thrown an error for a required missing path.")
+	}
+}
+
+func TestStringInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"s": []}`,
+		`{"s": 1}`,
+		`{"s": "reverse"}`,
+		`{"s": {"length": 3}}`,
+		`{"s": {"fn": "trim", "chars": 1}}`,
+		`{"s": {"fn": "trim", "length": 1}}`,
+		`{"s": "padLeft"}`,
+		`{"s": {"fn": "padLeft", "length": -1}}`,
+		`{"s": {"fn": "padLeft", "length": 3, "char": "00"}}`,
+		`{"s": {"fn": "substring", "start": 1.5}}`,
+		`{"s": {"fn": "truncate", "length": 1, "ellipsis": "..."}}`,
+		`{"s": {"fn": "replace", "old": ""}}`,
+		`{"s": {"fn": "replace", "old": "a", "count": 0}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareString(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid string spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// templatePart is either literal text or a placeholder of a template.
+type templatePart struct {
+	text        string
+	placeholder *templatePlaceholder
+}
+
+// templatePlaceholder is a `{{path | filter:arg | ...}}` reference of a template.
+type templatePlaceholder struct {
+	path       string
+	filters    []templateFilter
+	hasDefault bool
+}
+
+// templateFilter transforms the values of a placeholder. A placeholder has as many
+// values as its path expands to: none when it is missing, or several for wildcards
+// and arrays.
+type templateFilter func(values []string) ([]string, error)
+
+// templateFilters builds a filter from its arguments, validating them at load time.
+var templateFilters = map[string]func(args []string) (templateFilter, error){
+	"default": newDefaultFilter,
+	"upper":   newStringsFilter(strings.ToUpper),
+	"lower":   newStringsFilter(strings.ToLower),
+	"trim":    newStringsFilter(strings.TrimSpace),
+	"date":    newDateFilter,
+	"number":  newNumberFilter,
+	"join":    newJoinFilter,
+}
+
+// defaultTemplateSeparator joins the values of a placeholder without a join filter.
+const defaultTemplateSeparator = ", "
+
+// Template sets each target path to a string built from a template, such as
+// `"Order {{order.id}} for {{customer.name | upper}}"`. Templates are parsed once per
+// spec, see PrepareTemplate.
+func Template(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseTemplateSpecs)
+	if err != nil {
+		return nil, err
+	}
+	// every template is rendered from the input data, before any result is set
+	var results []exprResult
+	for k, parts := range parsed.(map[string][]templatePart) {
+		targets, err := expandWildcards(data, k, false, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, target := range targets {
+			rendered, err := renderTemplate(spec, data, parts, target.indexes)
+			if err != nil {
+				return nil, err
+			}
+			value, err := encodeJSON(rendered)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, exprResult{path: target.path, value: value})
+		}
+	}
+	for _, result := range results {
+		data, err = setJSONRaw(data, result.value, result.path, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// PrepareTemplate parses the templates of the spec ahead of transforming any data.
+func PrepareTemplate(spec *Config) error {
+	return spec.prepareWith(parseTemplateSpecs)
+}
+
+func parseTemplateSpecs(spec *Config) (interface{}, error) {
+	templates := make(map[string][]templatePart)
+	for k, v := range *spec.Spec {
+		source, ok := v.(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Template must be a string for key: %s", k))
+		}
+		parts, err := parseTemplate(source)
+		if err != nil {
+			return nil, SpecError(fmt.Sprintf("%v for key: %s", err, k))
+		}
+		templates[k] = parts
+	}
+	return templates, nil
+}
+
+// parseTemplate splits a template into literal text and placeholders.
+func parseTemplate(source string) ([]templatePart, error) {
+	var parts []templatePart
+	for {
+		open := strings.Index(source, "{{")
+		if open < 0 {
+			break
+		}
+		if open > 0 {
+			parts = append(parts, templatePart{text: source[:open]})
+		}
+		placeholder, length, err := parsePlaceholder(source[open+2:])
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, templatePart{placeholder: placeholder})
+		source = source[open+2+length:]
+	}
+	if source != "" {
+		parts = append(parts, templatePart{text: source})
+	}
+	return parts, nil
+}
+
+// parsePlaceholder parses the placeholder at the start of s, just after its `{{`, and
+// returns its length including the closing `}}`.
+func parsePlaceholder(s string) (*templatePlaceholder, int, error) {
+	// split the placeholder on pipes outside of quoted arguments
+	var segments []string
+	start := 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '|':
+			segments = append(segments, s[start:i])
+			start = i + 1
+		case strings.HasPrefix(s[i:], "}}"):
+			segments = append(segments, s[start:i])
+			p, err := newPlaceholder(segments)
+			return p, i + 2, err
+		}
+	}
+	return nil, 0, SpecError("Warn: Invalid spec. Unterminated template placeholder")
+}
+
+func newPlaceholder(segments []string) (*templatePlaceholder, error) {
+	p := &templatePlaceholder{path: strings.TrimSpace(segments[0])}
+	if p.path == "" {
+		return nil, SpecError("Warn: Invalid spec. Empty template placeholder")
+	}
+	for _, segment := range segments[1:] {
+		segment = strings.TrimSpace(segment)
+		name, argString := segment, ""
+		if colon := strings.IndexRune(segment, ':'); colon >= 0 {
+			name, argString = strings.TrimSpace(segment[:colon]), segment[colon+1:]
+		}
+		newFilter, ok := templateFilters[name]
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown template filter %q", name))
+		}
+		args, err := parseFilterArgs(argString)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := newFilter(args)
+		if err != nil {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Template filter %q: %v", name, err))
+		}
+		p.filters = append(p.filters, filter)
+		p.hasDefault = p.hasDefault || name == "default"
+	}
+	return p, nil
+}
+
+// parseFilterArgs parses comma-separated filter arguments, each a quoted string or a
+// bare word.
+func parseFilterArgs(s string) ([]string, error) {
+	var args []string
+	runes := []rune(strings.TrimSpace(s))
+	for i := 0; i < len(runes); {
+		var arg []rune
+		if quote := runes[i]; quote == '"' || quote == '\'' {
+			end := i + 1
+			for end < len(runes) && runes[end] != quote {
+				end++
+			}
+			if end == len(runes) {
+				return nil, SpecError("Warn: Invalid spec. Unterminated template filter argument")
+			}
+			arg, i = runes[i+1:end], end+1
+		} else {
+			end := i
+			for end < len(runes) && runes[end] != ',' {
+				end++
+			}
+			arg, i = []rune(strings.TrimSpace(string(runes[i:end]))), end
+		}
+		args = append(args, string(arg))
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+		if i < len(runes) {
+			if runes[i] != ',' {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unexpected %q in template filter arguments", runes[i]))
+			}
+			i++
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+		}
+	}
+	return args, nil
+}
+
+// renderTemplate renders the template for the target with the given wildcard indexes.
+func renderTemplate(spec *Config, data []byte, parts []templatePart, indexes []int) (string, error) {
+	var builder strings.Builder
+	for _, part := range parts {
+		if part.placeholder == nil {
+			builder.WriteString(part.text)
+			continue
+		}
+		values, err := part.placeholder.render(spec, data, indexes)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(values)
+	}
+	return builder.String(), nil
+}
+
+// render returns the filtered values of the placeholder, joined by the default
+// separator. Wildcards shared with the target refer to the same elements.
+func (p *templatePlaceholder) render(spec *Config, data []byte, indexes []int) (string, error) {
+	path := fillWildcards(p.path, indexes)
+	paths, err := expandWildcards(data, path, false, spec.KeySeparator)
+	if err != nil {
+		return "", err
+	}
+	var values []string
+	for _, wp := range paths {
+		raw, err := getJSONRaw(data, wp.path, false, spec.KeySeparator)
+		if err != nil {
+			return "", err
+		}
+		elements := [][]byte{raw}
+		// an array at a path without wildcards provides its elements
+		if raw[0] == '[' && wp.path == path {
+			if elements, err = arrayElements(raw); err != nil {
+				return "", err
+			}
+		}
+		for _, element := range elements {
+			decoded, err := decodeJSON(element)
+			if err != nil {
+				return "", err
+			}
+			if decoded != nil {
+				values = append(values, exprString(normalizeNumbers(decoded)))
+			}
+		}
+	}
+	if len(values) == 0 && spec.Require && !p.hasDefault {
+		return "", RequireError(fmt.Sprintf("Path does not exist: %s", path))
+	}
+	for _, filter := range p.filters {
+		if values, err = filter(values); err != nil {
+			return "", ParseError(fmt.Sprintf("%v in placeholder %s", err, p.path))
+		}
+	}
+	return strings.Join(values, defaultTemplateSeparator), nil
+}
+
+func argCount(args []string, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("takes %d arguments", min)
+		}
+		return fmt.Errorf("takes %d to %d arguments", min, max)
+	}
+	return nil
+}
+
+// newDefaultFilter returns its argument when the placeholder has no values.
+func newDefaultFilter(args []string) (templateFilter, error) {
+	if err := argCount(args, 1, 1); err != nil {
+		return nil, err
+	}
+	return func(values []string) ([]string, error) {
+		if len(values) == 0 {
+			return []string{args[0]}, nil
+		}
+		return values, nil
+	}, nil
+}
+
+// newStringsFilter returns a filter without arguments applying fn to every value.
+func newStringsFilter(fn func(string) string) func(args []string) (templateFilter, error) {
+	return func(args []string) (templateFilter, error) {
+		if err := argCount(args, 0, 0); err != nil {
+			return nil, err
+		}
+		return func(values []string) ([]string, error) {
+			result := make([]string, len(values))
+			for i, value := range values {
+				result[i] = fn(value)
+			}
+			return result, nil
+		}, nil
+	}
+}
+
+// newDateFilter formats times in the layout of its first argument. Values are parsed
+// in the layout of the optional second argument, time.RFC3339 by default. Either
+// layout may be a unix time format, such as `$unix` or `$unixms`.
+func newDateFilter(args []string) (templateFilter, error) {
+	if err := argCount(args, 1, 2); err != nil {
+		return nil, err
+	}
+	outputFormat, inputFormat := args[0], time.RFC3339
+	if len(args) == 2 {
+		inputFormat = args[1]
+	}
+	return func(values []string) ([]string, error) {
+		result := make([]string, len(values))
+		for i, value := range values {
+			t, ok := parseTime(value, inputFormat)
+			if !ok {
+				return nil, ParseError(fmt.Sprintf("Warn: Unable to parse time %q with format %s", value, inputFormat))
+			}
+			result[i] = formatTime(t, outputFormat)
+		}
+		return result, nil
+	}, nil
+}
+
+// newNumberFilter formats numbers with the number of decimals of its argument.
+func newNumberFilter(args []string) (templateFilter, error) {
+	if err := argCount(args, 1, 1); err != nil {
+		return nil, err
+	}
+	decimals, err := strconv.Atoi(args[0])
+	if err != nil || decimals < 0 {
+		return nil, fmt.Errorf("decimals must be a non-negative integer: %s", args[0])
+	}
+	return func(values []string) ([]string, error) {
+		result := make([]string, len(values))
+		for i, value := range values {
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return nil, ParseError(fmt.Sprintf("Warn: Unable to format non-numeric value %q", value))
+			}
+			result[i] = strconv.FormatFloat(f, 'f', decimals, 64)
+		}
+		return result, nil
+	}, nil
+}
+
+// newJoinFilter joins the values with the separator of its argument.
+func newJoinFilter(args []string) (templateFilter, error) {
+	if err := argCount(args, 1, 1); err != nil {
+		return nil, err
+	}
+	return func(values []string) ([]string, error) {
+		if len(values) == 0 {
+			return values, nil
+		}
+		return []string{strings.Join(values, args[0])}, nil
+	}, nil
+}
@@ -0,0 +1,108 @@
+package transform
+
+import "testing"
+
+func TestTemplate(t *testing.T) {
+	jsonIn := `{"order":{"id":42,"total":"1234.5","placed":"2020-03-04T05:06:07Z","at":1583298367,"atMs":1583298367123},"customer":{"first":" ann ","last":"Lee"},"tags":["a","b"],"items":[{"sku":"x","qty":2},{"sku":"y","qty":1}]}`
+	testCases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"paths", `Order {{order.id}} for {{customer.last}}`, `Order 42 for Lee`},
+		{"spaces in placeholders", `{{ order.id }}`, `42`},
+		{"string filters", `{{customer.first | trim | upper}} {{customer.last|lower}}`, `ANN lee`},
+		{"default", `{{customer.middle | default:"-"}} {{customer.last | default:"-"}}`, `- Lee`},
+		{"missing values are empty", `[{{customer.middle}}]`, `[]`},
+		{"date", `{{order.placed | date:"2006-01-02"}}`, `2020-03-04`},
+		{"date from unix", `{{order.at | date:"Jan 2, 2006", "$unix"}}`, `Mar 4, 2020`},
+		{"date to unix units", `{{order.placed | date:"$unixms"}} {{order.at | date:"$unixns", "$unix"}}`, `1583298367000 1583298367000000000`},
+		{"date between unix units", `{{order.atMs | date:"$unixus", "$unixms"}} {{order.atMs | date:"$unix", "$unixms"}}`, `1583298367123000 1583298367`},
+		{"number", `{{order.total | number:2}} {{order.id | number:1}}`, `1234.50 42.0`},
+		{"arrays are joined", `{{tags}}`, `a, b`},
+		{"wildcards are joined", `{{items[*].sku | upper | join:"/"}}`, `X/Y`},
+		{"quoted arguments may hold pipes and braces", `{{missing | default:'a|}}b'}}`, `a|}}b`},
+		{"literal text only", `no placeholders`, `no placeholders`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, _ := encodeJSON(tc.template)
+			cfg := getConfig(`{"out": `+string(encoded)+`}`, false)
+			kazaamOut, err := getTransformTestWrapper(Template, cfg, jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, _ := getJSONRaw(kazaamOut, "out", true, ".")
+			want, _ := encodeJSON(tc.want)
+			if string(got) != string(want) {
+				t.Errorf("got %s; want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestTemplateWildcardTarget(t *testing.T) {
+	spec := `{"items[*].label": "{{items[*].qty}} x {{items[*].sku | upper}} ({{currency}})"}`
+	jsonIn := `{"currency":"EUR","items":[{"sku":"x","qty":2},{"sku":"y","qty":1}]}`
+	jsonOut := `{"currency":"EUR","items":[{"sku":"x","qty":2,"label":"2 x X (EUR)"},{"sku":"y","qty":1,"label":"1 x Y (EUR)"}]}`
+
+	cfg := getConfig(spec, false)
+	kazaamOut, err := getTransformTestWrapper(Template, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		require bool
+	}{
+		{`{"out": "{{missing}}"}`, true},
+		{`{"out": "{{name | number:2}}"}`, false},
+		{`{"out": "{{name | date:\"2006\"}}"}`, false},
+	}
+
+	for _, tc := range testCases {
+		cfg := getConfig(tc.spec, tc.require)
+		_, err := getTransformTestWrapper(Template, cfg, `{"name":"ann"}`)
+		if err == nil {
+			t.Error("Should have thrown an error.")
+			t.Log("Spec:       ", tc.spec)
+		}
+	}
+
+	cfg := getConfig(`{"out": "{{missing | default:\"x\"}}"}`, true)
+	if _, err := getTransformTestWrapper(Template, cfg, `{}`); err != nil {
+		t.Errorf("A default should satisfy require: %v", err)
+	}
+}
+
+func TestTemplateInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"out": 1}`,
+		`{"out": "{{name"}`,
+		`{"out": "{{ }}"}`,
+		`{"out": "{{name | reverse}}"}`,
+		`{"out": "{{name | upper:1}}"}`,
+		`{"out": "{{name | default}}"}`,
+		`{"out": "{{name | default:\"a\" \"b\"}}"}`,
+		`{"out": "{{name | default:\"a}}"}`,
+		`{"out": "{{name | number:-1}}"}`,
+		`{"out": "{{name | date}}"}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareTemplate(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid template spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -2,125 +2,278 @@ package transform
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
+)
 
-	"github.com/qntfy/jsonparser"
+const (
+	unixFormat   = "$unix"
+	unixMsFormat = "$unixms"
+	unixUsFormat = "$unixus"
+	unixNsFormat = "$unixns"
+	nowFormat    = "$now"
 )
 
-// This is necessary for testing purposes
-var now = time.Now
+// unixUnits are the durations of one unit of the unix time formats.
+var unixUnits = map[string]time.Duration{
+	unixFormat:   time.Second,
+	unixMsFormat: time.Millisecond,
+	unixUsFormat: time.Microsecond,
+	unixNsFormat: time.Nanosecond,
+}
 
-const unixFormat = "$unix"
+// timestampSpec describes how the timestamps at a single path are parsed and
+// formatted.
+type timestampSpec struct {
+	// inputFormats are tried in order until one parses the value
+	inputFormats   []string
+	outputFormat   string
+	inputLocation  *time.Location
+	outputLocation *time.Location
+}
 
 // Timestamp parses and formats timestamp strings using the golang syntax
 func Timestamp(spec *Config, data []byte) ([]byte, error) {
-	for k, v := range *spec.Spec {
-		assertedV, vErr := v.(map[string]interface{})
-		if !vErr {
-			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
-		}
-		inputFormat, inputErr := assertedV["inputFormat"].(string)
-		if !inputErr {
-			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"inputFormat\" for key: %s", k))
-		}
-		outputFormat, outputErr := assertedV["outputFormat"].(string)
-		if !outputErr {
-			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"outputFormat\" for key: %s", k))
-		}
-		// check if an array wildcard is present and if it is, treat it the
-		// same as a key with an array
-		//if k[len(k)-2] == '*' {
-		//	k = k[:len(k)-3]
-		//}
-		var dataForV []byte
-		var err error
-
-		if inputFormat == "$now" {
-			t, err := now().MarshalText()
-			if err != nil {
-				return nil, err
-			}
-			dataForV = bookend(t, '"', '"')
-			// this is the standard format that `time.Now().String()` uses
-			inputFormat = time.RFC3339
-		} else {
-			// grab the data
-			dataForV, err = getJSONRaw(data, k, spec.Require, spec.KeySeparator)
+	parsed, err := spec.parsedSpec(parseTimestampSpecs)
+	if err != nil {
+		return nil, err
+	}
+	for k, ts := range parsed.(map[string]*timestampSpec) {
+		if ts.inputFormats[0] == nowFormat {
+			data, err = setJSONRaw(data, []byte(ts.format(spec.now())), k, spec.KeySeparator)
 			if err != nil {
 				return nil, err
 			}
-		}
-		// if the key is missing bail and keep iterating
-		if bytes.Compare(dataForV, []byte("null")) == 0 {
 			continue
 		}
-		// can only parse and format strings and arrays of strings, check the
-		// value type and handle accordingly
-		switch dataForV[0] {
-		case '"':
-			formattedItem, err := parseAndFormatValue(inputFormat, outputFormat, string(dataForV[1:len(dataForV)-1]))
+		paths, err := expandWildcards(data, k, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
 			if err != nil {
 				return nil, err
 			}
-			data, err = setJSONRaw(data, []byte(formattedItem), k, spec.KeySeparator)
-			if err != nil {
-				return nil, err
+			// if the key is missing bail and keep iterating
+			if bytes.Equal(dataForV, []byte("null")) {
+				continue
 			}
-		case '[':
-			var unformattedItems []string
-			_, err = jsonparser.ArrayEach(dataForV, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
-				unformattedItems = append(unformattedItems, string(value))
-			})
+			decoded, err := decodeJSON(dataForV)
 			if err != nil {
 				return nil, err
 			}
-			for idx, unformattedItem := range unformattedItems {
-				formattedItem, err := parseAndFormatValue(inputFormat, outputFormat, unformattedItem)
+			var formatted []byte
+			switch decodedTyped := decoded.(type) {
+			case string, json.Number:
+				formattedItem, err := ts.parseAndFormat(decodedTyped)
 				if err != nil {
 					return nil, err
 				}
-				// replacing the wildcard here feels hacky, but seems to be the
-				// quickest way to achieve the outcome we want
-				data, err = setJSONRaw(data, []byte(formattedItem), strings.Replace(k, "*", strconv.Itoa(idx), -1), spec.KeySeparator)
-				if err != nil {
-					return nil, err
+				formatted = []byte(formattedItem)
+			case []interface{}:
+				// every element of an array is formatted
+				formattedItems := make([][]byte, len(decodedTyped))
+				for i, item := range decodedTyped {
+					formattedItem, err := ts.parseAndFormat(item)
+					if err != nil {
+						return nil, err
+					}
+					formattedItems[i] = []byte(formattedItem)
 				}
+				formatted = joinArray(formattedItems)
+			default:
+				return nil, ParseError(fmt.Sprintf("Warn: Unknown type in message for key: %s", k))
+			}
+			data, err = setJSONRaw(data, formatted, p.path, spec.KeySeparator)
+			if err != nil {
+				return nil, err
 			}
-		default:
-			return nil, ParseError(fmt.Sprintf("Warn: Unknown type in message for key: %s", v))
 		}
 	}
 	return data, nil
 }
 
-// parseAndFormatValue generates a properly formatted timestamp
-func parseAndFormatValue(inputFormat, outputFormat, unformattedItem string) (string, error) {
-	var (
-		parsedItem    time.Time
-		formattedItem string
-		err           error
-	)
+// PrepareTimestamp parses the timestamp spec, loading its time zones, ahead of
+// transforming any data.
+func PrepareTimestamp(spec *Config) error {
+	return spec.prepareWith(parseTimestampSpecs)
+}
 
-	if inputFormat == unixFormat {
-		i, err := strconv.ParseInt(unformattedItem, 10, 64)
+func parseTimestampSpecs(spec *Config) (interface{}, error) {
+	specs := make(map[string]*timestampSpec)
+	for k, v := range *spec.Spec {
+		assertedV, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get value for key: %s", k))
+		}
+		ts, err := newTimestampSpec(assertedV, k, true)
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+		specs[k] = ts
+	}
+	return specs, nil
+}
+
+// newTimestampSpec parses the `inputFormat`, `outputFormat`, `inputTimezone` and
+// `outputTimezone` options for key k. Unless the formats are required, the input
+// format is time.RFC3339 by default and the output format is the first input format,
+// or time.RFC3339 for `$now`.
+func newTimestampSpec(options map[string]interface{}, k string, requireFormats bool) (*timestampSpec, error) {
+	ts := &timestampSpec{}
+	// the input format may be a list of formats to try in order
+	switch inputFormat := options["inputFormat"].(type) {
+	case string:
+		ts.inputFormats = []string{inputFormat}
+	case []interface{}:
+		for _, format := range inputFormat {
+			formatStr, ok := format.(string)
+			if !ok || formatStr == nowFormat {
+				return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. \"inputFormat\" must be a string or a list of formats for key: %s", k))
+			}
+			ts.inputFormats = append(ts.inputFormats, formatStr)
+		}
+	case nil:
+		if !requireFormats {
+			ts.inputFormats = []string{time.RFC3339}
+		}
+	}
+	if len(ts.inputFormats) == 0 {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"inputFormat\" for key: %s", k))
+	}
+	outputFormat, ok := options["outputFormat"]
+	if !ok && !requireFormats {
+		outputFormat = ts.inputFormats[0]
+		if outputFormat == nowFormat {
+			outputFormat = time.RFC3339
 		}
-		parsedItem = time.Unix(i, 0)
-	} else {
-		parsedItem, err = time.Parse(inputFormat, unformattedItem)
+	}
+	if ts.outputFormat, ok = outputFormat.(string); !ok {
+		return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unable to get \"outputFormat\" for key: %s", k))
+	}
+	for name, location := range map[string]**time.Location{"inputTimezone": &ts.inputLocation, "outputTimezone": &ts.outputLocation} {
+		timezone, ok := options[name]
+		if !ok {
+			continue
+		}
+		timezoneStr, ok := timezone.(string)
+		if !ok {
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. %q must be a string for key: %s", name, k))
+		}
+		loaded, err := time.LoadLocation(timezoneStr)
 		if err != nil {
-			return "", err
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown %s %q for key: %s", name, timezoneStr, k))
 		}
+		*location = loaded
+	}
+	return ts, nil
+}
+
+// parseAndFormat parses a decoded string or number and returns it as a raw json
+// string in the output format.
+func (ts *timestampSpec) parseAndFormat(value interface{}) (string, error) {
+	parsedItem, err := ts.parseValue(value)
+	if err != nil {
+		return "", err
 	}
+	return ts.format(parsedItem), nil
+}
+
+// parseValue parses a decoded string or number.
+func (ts *timestampSpec) parseValue(value interface{}) (time.Time, error) {
+	switch valueTyped := value.(type) {
+	case string:
+		return ts.parse(valueTyped)
+	case json.Number:
+		return ts.parse(valueTyped.String())
+	}
+	return time.Time{}, ParseError(fmt.Sprintf("Warn: Unable to parse non-string timestamp %v", value))
+}
 
-	if outputFormat == unixFormat {
-		formattedItem = strconv.FormatInt(parsedItem.Unix(), 10)
-	} else {
-		formattedItem = parsedItem.Format(outputFormat)
+// parse tries each input format in order and returns the first successfully parsed
+// time. Times without a zone are parsed in the input time zone, UTC by default; unix
+// times are in the input time zone, or local time by default.
+func (ts *timestampSpec) parse(unformattedItem string) (time.Time, error) {
+	var err error
+	for _, inputFormat := range ts.inputFormats {
+		var parsedItem time.Time
+		if unit, ok := unixUnits[inputFormat]; ok {
+			parsedItem, err = parseUnixTime(unformattedItem, unit)
+			if err == nil && ts.inputLocation != nil {
+				parsedItem = parsedItem.In(ts.inputLocation)
+			}
+		} else if ts.inputLocation != nil {
+			parsedItem, err = time.ParseInLocation(inputFormat, unformattedItem, ts.inputLocation)
+		} else {
+			parsedItem, err = time.Parse(inputFormat, unformattedItem)
+		}
+		if err == nil {
+			return parsedItem, nil
+		}
+	}
+	if len(ts.inputFormats) > 1 {
+		return time.Time{}, ParseError(fmt.Sprintf("Warn: Unable to parse timestamp %q with any of the input formats", unformattedItem))
+	}
+	return time.Time{}, err
+}
+
+// parseUnixTime parses a number of units since the epoch. Fractional seconds are
+// supported for `$unix`.
+func parseUnixTime(s string, unit time.Duration) (time.Time, error) {
+	perSecond := int64(time.Second / unit)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(i/perSecond, i%perSecond*int64(unit)), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if unit != time.Second {
+		return time.Time{}, ParseError(fmt.Sprintf("Warn: Unable to parse fractional unix time %s", s))
+	}
+	seconds, fraction := math.Modf(f)
+	return time.Unix(int64(seconds), int64(fraction*float64(time.Second))), nil
+}
+
+// format returns the time as a raw json string in the output format and time zone.
+func (ts *timestampSpec) format(parsedItem time.Time) string {
+	if ts.outputLocation != nil {
+		parsedItem = parsedItem.In(ts.outputLocation)
+	}
+	return strings.Join([]string{"\"", formatTime(parsedItem, ts.outputFormat), "\""}, "")
+}
+
+// formatTime formats t in the given layout, or in units since the epoch for `$unix`,
+// `$unixms`, `$unixus` and `$unixns`.
+func formatTime(t time.Time, format string) string {
+	if unit, ok := unixUnits[format]; ok {
+		perSecond := int64(time.Second / unit)
+		return strconv.FormatInt(t.Unix()*perSecond+int64(t.Nanosecond())/int64(unit), 10)
+	}
+	return t.Format(format)
+}
+
+// parseTime parses a decoded string or number in the given layout, or in units since
+// the epoch for `$unix`, `$unixms`, `$unixus` and `$unixns`. It returns false when
+// the value is not a time in that format.
+func parseTime(value interface{}, format string) (time.Time, bool) {
+	if unit, ok := unixUnits[format]; ok {
+		number, _ := exprToNumber([]interface{}{value})
+		if !isNumber(number) {
+			return time.Time{}, false
+		}
+		t, err := parseUnixTime(exprString(number), unit)
+		return t, err == nil
+	}
+	valueStr, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
 	}
-	return strings.Join([]string{"\"", formattedItem, "\""}, ""), nil
+	t, err := time.Parse(format, valueStr)
+	return t, err == nil
 }
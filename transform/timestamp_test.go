@@ -24,9 +24,8 @@ func TestTimestamp(t *testing.T) {
 }
 
 func TestTimestampWithNow(t *testing.T) {
-	// setup a custom `now()` for testing purposes, replacing the global in
-	// `timestamp.go`
-	now = func() time.Time {
+	// setup a custom clock for testing purposes
+	clock := func() time.Time {
 		t, _ := time.Parse(time.RFC1123Z, "Fri, 08 Sep 2017 10:06:05 -0400")
 		return t
 	}
@@ -34,6 +33,7 @@ func TestTimestampWithNow(t *testing.T) {
 	jsonOut := `{"timestampNow":"2017-09-08T10:06:05-0400","timestampA":"Sun Jul 23 08:15:27 +0000 2017","topLevel":{"timestampB":"Fri Jul 21 08:15:27 +0000 2017"},"timestampC":[{"datetime":"Sat Jul 22 08:15:27 +0000 2017"},{"datetime":"Sun Jul 23 08:15:27 +0000 2017"},{"datetime":"Mon Jul 24 08:15:27 +0000 2017"}]}`
 
 	cfg := getConfig(spec, false)
+	cfg.Clock = clock
 	kazaamOut, _ := getTransformTestWrapper(Timestamp, cfg, timestampJSON)
 	areEqual, _ := checkJSONBytesEqual(kazaamOut, []byte(jsonOut))
 
@@ -172,7 +172,8 @@ func TestParseAndFormatValue(t *testing.T) {
 		{"$unix", "\"1500621327\""},
 	}
 	for _, testItem := range parseAndFormatTests {
-		actual, _ := parseAndFormatValue(inputFormat, testItem.outputFormat, inputTimestamp)
+		ts := &timestampSpec{inputFormats: []string{inputFormat}, outputFormat: testItem.outputFormat}
+		actual, _ := ts.parseAndFormat(inputTimestamp)
 		if actual != testItem.expectedOutput {
 			t.Error("Error data does not match expectation.")
 			t.Log("Expected:   ", testItem.expectedOutput)
@@ -196,7 +197,8 @@ func TestParseAndFormatValueOutputUnix(t *testing.T) {
 		{"$unix", "1500621327", "\"1500621327\""},
 	}
 	for _, testItem := range parseAndFormatTests {
-		actual, _ := parseAndFormatValue(testItem.inputFormat, "$unix", testItem.inputTimestamp)
+		ts := &timestampSpec{inputFormats: []string{testItem.inputFormat}, outputFormat: "$unix"}
+		actual, _ := ts.parseAndFormat(testItem.inputTimestamp)
 		if actual != testItem.expectedOutput {
 			t.Error("Error data does not match expectation.", testItem.inputFormat)
 			t.Log("Expected:   ", testItem.expectedOutput)
@@ -204,3 +206,99 @@ func TestParseAndFormatValueOutputUnix(t *testing.T) {
 		}
 	}
 }
+
+func TestTimestampEpochsAndTimezones(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"unix milliseconds number", `{"t": {"inputFormat": "$unixms", "outputFormat": "2006-01-02T15:04:05.000Z07:00", "outputTimezone": "UTC"}}`, `{"t":1500621327123}`, `{"t":"2017-07-21T07:15:27.123Z"}`},
+		{"unix microseconds string", `{"t": {"inputFormat": "$unixus", "outputFormat": "$unixms"}}`, `{"t":"1500621327123456"}`, `{"t":"1500621327123"}`},
+		{"unix nanoseconds", `{"t": {"inputFormat": "$unixns", "outputFormat": "$unixus"}}`, `{"t":1500621327123456789}`, `{"t":"1500621327123456"}`},
+		{"negative unix milliseconds", `{"t": {"inputFormat": "$unixms", "outputFormat": "2006-01-02T15:04:05.000Z07:00", "outputTimezone": "UTC"}}`, `{"t":-1500}`, `{"t":"1969-12-31T23:59:58.500Z"}`},
+		{"fractional unix seconds", `{"t": {"inputFormat": "$unix", "outputFormat": "$unixms"}}`, `{"t":1500621327.25}`, `{"t":"1500621327250"}`},
+		{"output nanoseconds", `{"t": {"inputFormat": "2006-01-02T15:04:05.999999999Z07:00", "outputFormat": "$unixns"}}`, `{"t":"2017-07-21T07:15:27.000000042Z"}`, `{"t":"1500621327000000042"}`},
+		{"input time zone", `{"t": {"inputFormat": "2006-01-02 15:04", "outputFormat": "2006-01-02T15:04:05Z07:00", "inputTimezone": "America/New_York"}}`, `{"t":"2017-07-21 08:15"}`, `{"t":"2017-07-21T08:15:00-04:00"}`},
+		{"input time zone does not override offsets", `{"t": {"inputFormat": "2006-01-02T15:04:05Z07:00", "outputFormat": "$unix", "inputTimezone": "Asia/Tokyo"}}`, `{"t":"2017-07-21T07:15:27Z"}`, `{"t":"1500621327"}`},
+		{"output time zone", `{"t": {"inputFormat": "2006-01-02T15:04:05Z07:00", "outputFormat": "2006-01-02 15:04 MST", "outputTimezone": "Asia/Tokyo"}}`, `{"t":"2017-07-21T07:15:27Z"}`, `{"t":"2017-07-21 16:15 JST"}`},
+		{"unix input in a time zone", `{"t": {"inputFormat": "$unix", "outputFormat": "2006-01-02T15:04:05Z07:00", "inputTimezone": "America/New_York"}}`, `{"t":1500621327}`, `{"t":"2017-07-21T03:15:27-04:00"}`},
+		{"fallback input formats", `{"t[*]": {"inputFormat": ["2006-01-02", "$unixms", "02/01/2006"], "outputFormat": "2006-01-02"}}`, `{"t":["2017-07-21","21/07/2017",1500621327123]}`, `{"t":["2017-07-21","2017-07-21","2017-07-21"]}`},
+		{"arrays are formatted element-wise", `{"t": {"inputFormat": "$unix", "outputFormat": "$unixms"}}`, `{"t":[1,"2"]}`, `{"t":["1000","2000"]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Timestamp, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestTimestampNowInTimezone(t *testing.T) {
+	spec := `{"t": {"inputFormat": "$now", "outputFormat": "2006-01-02T15:04:05Z07:00", "outputTimezone": "Asia/Tokyo"}}`
+	jsonOut := `{"t":"2017-07-21T16:15:27+09:00"}`
+
+	cfg := getConfig(spec, false)
+	cfg.Clock = func() time.Time { return time.Date(2017, 7, 21, 7, 15, 27, 0, time.UTC) }
+	kazaamOut, _ := getTransformTestWrapper(Timestamp, cfg, `{}`)
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+}
+
+func TestTimestampErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec string
+		in   string
+		want string
+	}{
+		{"no input format matches", `{"t": {"inputFormat": ["2006-01-02", "$unix"], "outputFormat": "$unix"}}`, `{"t":"July 21"}`, `Warn: Unable to parse timestamp "July 21" with any of the input formats`},
+		{"fractional milliseconds", `{"t": {"inputFormat": "$unixms", "outputFormat": "$unix"}}`, `{"t":1.5}`, "Warn: Unable to parse fractional unix time 1.5"},
+		{"boolean", `{"t": {"inputFormat": "$unix", "outputFormat": "$unix"}}`, `{"t":true}`, "Warn: Unknown type in message for key: t"},
+		{"boolean in an array", `{"t": {"inputFormat": "$unix", "outputFormat": "$unix"}}`, `{"t":[true]}`, "Warn: Unable to parse non-string timestamp true"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			_, err := getTransformTestWrapper(Timestamp, cfg, tc.in)
+			if err == nil || err.Error() != tc.want {
+				t.Error("Error data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", err)
+			}
+		})
+	}
+}
+
+func TestTimestampInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"t": {"inputFormat": [], "outputFormat": "$unix"}}`,
+		`{"t": {"inputFormat": ["$unix", 1], "outputFormat": "$unix"}}`,
+		`{"t": {"inputFormat": ["$unix", "$now"], "outputFormat": "$unix"}}`,
+		`{"t": {"inputFormat": "$unix", "outputFormat": 1}}`,
+		`{"t": {"inputFormat": "$unix", "outputFormat": "$unix", "inputTimezone": "Mars/Olympus_Mons"}}`,
+		`{"t": {"inputFormat": "$unix", "outputFormat": "$unix", "outputTimezone": 2}}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareTimestamp(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid timestamp spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build go1.15
+// +build go1.15
+
+package transform
+
+// embed the time zone database so that time zone tests don't depend on the host
+import _ "time/tzdata"
@@ -0,0 +1,120 @@
+package transform
+
+import (
+	"fmt"
+)
+
+// uniqueSpec describes the array to de-duplicate.
+type uniqueSpec struct {
+	path       string
+	targetPath string
+	by         string
+	keepLast   bool
+}
+
+// Unique removes duplicate elements of an array, comparing whole elements or the
+// value at a sub-path of the elements, and keeping the first or last occurrence.
+func Unique(spec *Config, data []byte) ([]byte, error) {
+	parsed, err := spec.parsedSpec(parseUniqueSpec)
+	if err != nil {
+		return nil, err
+	}
+	u := parsed.(*uniqueSpec)
+	return transformArrays(spec, data, u.path, u.targetPath, func(array []byte) ([]byte, error) {
+		return u.unique(array, spec.KeySeparator)
+	})
+}
+
+// PrepareUnique parses the unique spec ahead of transforming any data.
+func PrepareUnique(spec *Config) error {
+	return spec.prepareWith(parseUniqueSpec)
+}
+
+func parseUniqueSpec(spec *Config) (interface{}, error) {
+	u := &uniqueSpec{by: "$"}
+	var ok bool
+	if u.path, ok = (*spec.Spec)["path"].(string); !ok {
+		return nil, SpecError("Warn: Invalid spec. Unable to get \"path\"")
+	}
+	if targetPath, ok := (*spec.Spec)["targetPath"]; ok {
+		if u.targetPath, ok = targetPath.(string); !ok {
+			return nil, SpecError("Warn: Invalid spec. \"targetPath\" must be a string")
+		}
+	}
+	if by, ok := (*spec.Spec)["by"]; ok {
+		if u.by, ok = by.(string); !ok {
+			return nil, SpecError("Warn: Invalid spec. \"by\" must be a string")
+		}
+	}
+	if keep, ok := (*spec.Spec)["keep"]; ok {
+		switch keep {
+		case "first":
+		case "last":
+			u.keepLast = true
+		default:
+			return nil, SpecError(fmt.Sprintf("Warn: Invalid spec. Unknown keep: %v", keep))
+		}
+	}
+	return u, nil
+}
+
+// unique returns the raw array without duplicates. When de-duplicating by a sub-path,
+// elements without a value at that path are always kept.
+func (u *uniqueSpec) unique(array []byte, keySeparator string) ([]byte, error) {
+	elements, err := arrayElements(array)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(elements))
+	for i, element := range elements {
+		if keys[i], err = u.key(element, keySeparator); err != nil {
+			return nil, err
+		}
+	}
+
+	kept := make([]bool, len(elements))
+	seen := make(map[string]bool)
+	for n := range elements {
+		i := n
+		if u.keepLast {
+			i = len(elements) - 1 - n
+		}
+		if keys[i] == "" || !seen[keys[i]] {
+			kept[i] = true
+			seen[keys[i]] = true
+		}
+	}
+	var result [][]byte
+	for i, element := range elements {
+		if kept[i] {
+			result = append(result, element)
+		}
+	}
+	return joinArray(result), nil
+}
+
+// key returns a canonical encoding of the value compared for element, so that equal
+// values, such as objects with the same keys in another order or 1 and 1.0, share a
+// key. An empty key means the element has no value at the `by` path.
+func (u *uniqueSpec) key(element []byte, keySeparator string) (string, error) {
+	raw := element
+	if u.by != "$" {
+		var err error
+		if raw, err = getJSONRaw(element, u.by, false, keySeparator); err != nil {
+			return "", err
+		}
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return "", err
+	}
+	value := normalizeNumbers(decoded)
+	if value == nil && u.by != "$" {
+		return "", nil
+	}
+	encoded, err := encodeExprValue(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,83 @@
+package transform
+
+import "testing"
+
+func TestUnique(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		jsonIn  string
+		jsonOut string
+	}{
+		{
+			"whole values",
+			`{"path": "tags"}`,
+			`{"tags":["a","b","a",1,1.0,"1",null,null]}`,
+			`{"tags":["a","b",1,"1",null]}`,
+		},
+		{
+			"objects regardless of key order",
+			`{"path": "items"}`,
+			`{"items":[{"a":1,"b":2},{"b":2,"a":1},{"a":1}]}`,
+			`{"items":[{"a":1,"b":2},{"a":1}]}`,
+		},
+		{
+			"by key keeping first",
+			`{"path": "users", "by": "id"}`,
+			`{"users":[{"id":1,"v":"a"},{"id":2,"v":"b"},{"id":1,"v":"c"},{"v":"d"},{"v":"e"}]}`,
+			`{"users":[{"id":1,"v":"a"},{"id":2,"v":"b"},{"v":"d"},{"v":"e"}]}`,
+		},
+		{
+			"by key keeping last",
+			`{"path": "users", "by": "id", "keep": "last"}`,
+			`{"users":[{"id":1,"v":"a"},{"id":2,"v":"b"},{"id":1,"v":"c"}]}`,
+			`{"users":[{"id":2,"v":"b"},{"id":1,"v":"c"}]}`,
+		},
+		{
+			"nested arrays under wildcards to target path",
+			`{"path": "groups[*].tags", "targetPath": "groups[*].distinct"}`,
+			`{"groups":[{"tags":["x","x"]},{"tags":["y"]}]}`,
+			`{"groups":[{"tags":["x","x"],"distinct":["x"]},{"tags":["y"],"distinct":["y"]}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getConfig(tc.spec, false)
+			kazaamOut, err := getTransformTestWrapper(Unique, cfg, tc.jsonIn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != tc.jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestUniqueRequire(t *testing.T) {
+	cfg := getConfig(`{"path": "missing"}`, true)
+	_, err := getTransformTestWrapper(Unique, cfg, `{"name":"a"}`)
+	if err == nil {
+		t.Error("Should have thrown an error with require.")
+	}
+}
+
+func TestUniqueInvalidSpec(t *testing.T) {
+	testCases := []string{
+		`{"by": "a"}`,
+		`{"path": "a", "by": 1}`,
+		`{"path": "a", "keep": "any"}`,
+		`{"path": "a", "targetPath": true}`,
+	}
+
+	for _, spec := range testCases {
+		cfg := getConfig(spec, false)
+		if err := PrepareUnique(&cfg); err == nil {
+			t.Error("Should have thrown a SpecError for an invalid unique spec.")
+			t.Log("Spec:       ", spec)
+		}
+	}
+}
@@ -3,10 +3,14 @@ package transform
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/qntfy/jsonparser"
 )
@@ -39,6 +43,75 @@ type Config struct {
 	Require      bool                    `json:"require,omitempty"`
 	InPlace      bool                    `json:"inplace,omitempty"`
 	KeySeparator string                  `json:"keySeparator"`
+
+	// HashKeys holds the named secret keys of keyed hash algorithms, which are
+	// registered on the kazaam Config rather than written in the spec
+	HashKeys map[string][]byte `json:"-"`
+	// LookupTables holds the named tables of the lookup transform, registered on the
+	// kazaam Config
+	LookupTables map[string]map[string]interface{} `json:"-"`
+	// Clock returns the current time, e.g. for `$now`, and is time.Now when nil
+	Clock func() time.Time `json:"-"`
+	// Rand is the source of randomness, e.g. of version 4 UUIDs, and is crypto/rand
+	// when nil
+	Rand io.Reader `json:"-"`
+
+	// prepared holds the parsed form of Spec cached at load time, see prepareWith
+	prepared interface{}
+}
+
+// specParser builds the document-independent form of a transform spec, such as
+// compiled regular expressions, so that it is not rebuilt for every document.
+type specParser func(spec *Config) (interface{}, error)
+
+// prepareWith parses the spec once and caches the result on the Config.
+func (c *Config) prepareWith(parse specParser) error {
+	parsed, err := parse(c)
+	if err != nil {
+		return err
+	}
+	c.prepared = parsed
+	return nil
+}
+
+// parsedSpec returns the result cached by prepareWith, or parses the spec on the
+// fly when the Config was not prepared, e.g. when a transform is called directly. A
+// missing spec is parsed as an empty one.
+func (c *Config) parsedSpec(parse specParser) (interface{}, error) {
+	if c != nil && c.prepared != nil {
+		return c.prepared, nil
+	}
+	return parse(c.orEmpty())
+}
+
+// orEmpty returns the Config, or an empty one with the default key separator when
+// the spec is missing, e.g. for `{"operation": "flatten"}`.
+func (c *Config) orEmpty() *Config {
+	if c == nil {
+		return &Config{Spec: &map[string]interface{}{}, KeySeparator: "."}
+	}
+	if c.Spec == nil {
+		empty := *c
+		empty.Spec = &map[string]interface{}{}
+		return &empty
+	}
+	return c
+}
+
+// now returns the current time of the Config's Clock.
+func (c *Config) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+	return time.Now()
+}
+
+// random returns the Config's source of randomness.
+func (c *Config) random() io.Reader {
+	if c.Rand != nil {
+		return c.Rand
+	}
+	return rand.Reader
 }
 
 var (
@@ -293,3 +366,220 @@ func HandleUnquotedStrings(value []byte, dt jsonparser.ValueType) []byte {
 	}
 	return value
 }
+
+// decodeJSON unmarshals a raw json value, keeping numbers as json.Number so that
+// integers are not rounded through float64.
+func decodeJSON(raw []byte) (interface{}, error) {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to decode json value: %s", raw))
+	}
+	return v, nil
+}
+
+// encodeJSON marshals a value to raw json without escaping HTML characters.
+func encodeJSON(v interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to coerce element to json string: %v", v))
+	}
+	// Encode always terminates the value with a newline
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+// arrayElements returns the raw elements of a json array, with strings quoted.
+func arrayElements(array []byte) ([][]byte, error) {
+	var elements [][]byte
+	_, err := jsonparser.ArrayEach(array, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		elements = append(elements, HandleUnquotedStrings(value, dataType))
+	})
+	if err != nil {
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to parse array: %v", err))
+	}
+	return elements, nil
+}
+
+// joinArray builds a raw json array from raw elements.
+func joinArray(elements [][]byte) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteByte('[')
+	for i, element := range elements {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(element)
+	}
+	buffer.WriteByte(']')
+	return buffer.Bytes()
+}
+
+// rawField is a key of a json object along with its raw value.
+type rawField struct {
+	key   string
+	value []byte
+}
+
+// joinObject builds a raw json object from raw fields, in order.
+func joinObject(fields []rawField) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encodedKey, err := encodeJSON(field.key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(field.value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// objectFields returns the fields of a raw json object, in order, with strings quoted.
+func objectFields(object []byte) ([]rawField, error) {
+	var fields []rawField
+	err := jsonparser.ObjectEach(object, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		keyStr, err := jsonparser.ParseString(key)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, rawField{key: keyStr, value: HandleUnquotedStrings(value, dataType)})
+		return nil
+	})
+	if err != nil {
+		return nil, ParseError(fmt.Sprintf("Warn: Unable to parse object: %v", err))
+	}
+	return fields, nil
+}
+
+// transformArrays applies fn to the raw array at path, or at every path it expands to
+// when it contains `[*]` wildcards, and sets the result in place or at targetPath,
+// whose wildcards are filled with the same indexes. A path of `$` refers to a
+// top-level array. Paths that do not hold an array are skipped, or are an error with
+// Require set.
+func transformArrays(spec *Config, data []byte, path, targetPath string, fn func(array []byte) ([]byte, error)) ([]byte, error) {
+	return transformContainers(spec, data, path, targetPath, '[', fn)
+}
+
+// transformObjects is the equivalent of transformArrays for objects.
+func transformObjects(spec *Config, data []byte, path, targetPath string, fn func(object []byte) ([]byte, error)) ([]byte, error) {
+	return transformContainers(spec, data, path, targetPath, '{', fn)
+}
+
+func transformContainers(spec *Config, data []byte, path, targetPath string, open byte, fn func(container []byte) ([]byte, error)) ([]byte, error) {
+	kind := "an array"
+	if open == '{' {
+		kind = "an object"
+	}
+	if path == "$" {
+		trimmed := bytes.TrimSpace(data)
+		if len(trimmed) == 0 || trimmed[0] != open {
+			if spec.Require {
+				return nil, ParseError(fmt.Sprintf("Warn: Expected %s at the top level", kind))
+			}
+			return data, nil
+		}
+		result, err := fn(trimmed)
+		if err != nil || targetPath == "" {
+			return result, err
+		}
+		return setJSONRaw(data, result, targetPath, spec.KeySeparator)
+	}
+	paths, err := expandWildcards(data, path, spec.Require, spec.KeySeparator)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		dataForV, err := getJSONRaw(data, p.path, spec.Require, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+		if dataForV[0] != open {
+			if spec.Require {
+				return nil, ParseError(fmt.Sprintf("Warn: Expected %s at %s", kind, p.path))
+			}
+			continue
+		}
+		result, err := fn(dataForV)
+		if err != nil {
+			return nil, err
+		}
+		target := p.path
+		if targetPath != "" {
+			target = fillWildcards(targetPath, p.indexes)
+		}
+		data, err = setJSONRaw(data, result, target, spec.KeySeparator)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// wildcardPath is a concrete path obtained by expanding the `[*]` references of a
+// kazaam path, along with the array index substituted for each of them.
+type wildcardPath struct {
+	path    string
+	indexes []int
+}
+
+// expandWildcards resolves every `[*]` reference in path against data and returns
+// one concrete path per matching array element. A path without wildcards is
+// returned as-is, whether or not it exists in data.
+func expandWildcards(data []byte, path string, pathRequired bool, keySeparator string) ([]wildcardPath, error) {
+	return expandWildcardsWithIndexes(data, path, pathRequired, keySeparator, nil)
+}
+
+func expandWildcardsWithIndexes(data []byte, path string, pathRequired bool, keySeparator string, indexes []int) ([]wildcardPath, error) {
+	wildcard := strings.Index(path, "[*]")
+	if wildcard < 0 {
+		return []wildcardPath{{path: path, indexes: indexes}}, nil
+	}
+	arrayPath, afterPath := path[:wildcard], path[wildcard+3:]
+	array, err := getJSONRaw(data, arrayPath, pathRequired, keySeparator)
+	if err != nil {
+		return nil, err
+	}
+	var arraySize int
+	if array[0] == '[' {
+		_, err = jsonparser.ArrayEach(array, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+			arraySize++
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var paths []wildcardPath
+	for i := 0; i < arraySize; i++ {
+		elementPath := strings.Join([]string{arrayPath, "[", strconv.Itoa(i), "]", afterPath}, "")
+		// cap the slice so that sibling elements do not share index storage
+		expanded, err := expandWildcardsWithIndexes(data, elementPath, pathRequired, keySeparator, append(indexes[:len(indexes):len(indexes)], i))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded...)
+	}
+	return paths, nil
+}
+
+// fillWildcards replaces the `[*]` references in path, in order, with the given
+// array indexes. Wildcards beyond the number of indexes are left in place.
+func fillWildcards(path string, indexes []int) string {
+	for _, index := range indexes {
+		wildcard := strings.Index(path, "[*]")
+		if wildcard < 0 {
+			break
+		}
+		path = strings.Join([]string{path[:wildcard], "[", strconv.Itoa(index), "]", path[wildcard+3:]}, "")
+	}
+	return path
+}
@@ -219,3 +219,49 @@ func TestGetJSONRawBadIndex(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestExpandWildcards(t *testing.T) {
+	data := []byte(`{"a":[{"b":[1,2]},{"b":[]},{"b":[3]}],"c":"d"}`)
+	testCases := []struct {
+		path     string
+		expected []wildcardPath
+	}{
+		{"c", []wildcardPath{{path: "c"}}},
+		{"missing[*].b", nil},
+		{"a[*].b", []wildcardPath{{"a[0].b", []int{0}}, {"a[1].b", []int{1}}, {"a[2].b", []int{2}}}},
+		{"a[*].b[*]", []wildcardPath{{"a[0].b[0]", []int{0, 0}}, {"a[0].b[1]", []int{0, 1}}, {"a[2].b[0]", []int{2, 0}}}},
+	}
+
+	for _, tc := range testCases {
+		paths, err := expandWildcards(data, tc.path, false, ".")
+		if err != nil {
+			t.Errorf("unexpected error expanding %s: %v", tc.path, err)
+		}
+		if !reflect.DeepEqual(paths, tc.expected) {
+			t.Errorf("got %v; want %v", paths, tc.expected)
+		}
+	}
+
+	if _, err := expandWildcards(data, "missing[*].b", true, "."); err != NonExistentPath {
+		t.Errorf("got %v; want %v", err, NonExistentPath)
+	}
+}
+
+func TestFillWildcards(t *testing.T) {
+	testCases := []struct {
+		path     string
+		indexes  []int
+		expected string
+	}{
+		{"a[*].b", nil, "a[*].b"},
+		{"a[*].b[*]", []int{2}, "a[2].b[*]"},
+		{"a[*].b[*]", []int{2, 0}, "a[2].b[0]"},
+		{"a.b", []int{1}, "a.b"},
+	}
+
+	for _, tc := range testCases {
+		if path := fillWildcards(tc.path, tc.indexes); path != tc.expected {
+			t.Errorf("got %s; want %s", path, tc.expected)
+		}
+	}
+}
@@ -1,13 +1,29 @@
 package transform
 
 import (
+	"encoding/binary"
+	"io"
 	"strings"
+	"time"
 
 	uuid "github.com/gofrs/uuid"
 )
 
 var (
-	versionError = SpecError("Please set version 3 || 4 || 5")
+	versionError = SpecError("Please set version 1 || 3 || 4 || 5 || 6 || 7 || \"ulid\"")
+)
+
+const (
+	// uuidV6 and uuidV7 are the time-ordered versions, not provided by gofrs/uuid
+	uuidV6 byte = 6
+	uuidV7 byte = 7
+	// ulidVersion stands for the `"ulid"` version, which is not a UUID version
+	ulidVersion = 0
+	// gregorianOffset is the number of 100ns intervals between the start of the
+	// gregorian calendar, the epoch of v1 and v6 UUIDs, and the unix epoch
+	gregorianOffset = 122192928000000000
+	// crockfordBase32 is the alphabet of ULIDs
+	crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
 )
 
 // UUID tries to generate a UUID based on spec components
@@ -21,7 +37,7 @@ func UUID(spec *Config, data []byte) ([]byte, error) {
 			return nil, SpecError("Invalid Spec for UUID")
 		}
 		version := getUUIDVersion(uuidSpec)
-		if version < 3 || version > 5 {
+		if version < 0 {
 			return nil, versionError
 		}
 
@@ -29,11 +45,38 @@ func UUID(spec *Config, data []byte) ([]byte, error) {
 		var err error
 
 		switch version {
+		case 1, 6:
+			// the time-based versions use a random clock sequence and node, rather
+			// than the hardware address, so that they only depend on the Config
+			u, err = newTimeUUID(spec, byte(version))
+			if err != nil {
+				return nil, err
+			}
+
 		case 4:
-			u, err = uuid.NewV4()
+			// read from the Config's source of randomness, rather than uuid.NewV4, so
+			// that the UUIDs can be reproduced
+			if _, err = io.ReadFull(spec.random(), u[:]); err != nil {
+				return nil, err
+			}
+			u.SetVersion(uuid.V4)
+			u.SetVariant(uuid.VariantRFC4122)
+
+		case 7, ulidVersion:
+			// both are a unix time in milliseconds followed by random bits
+			u, err = newUnixTimeID(spec)
 			if err != nil {
 				return nil, err
 			}
+			if version == ulidVersion {
+				data, err = setJSONRaw(data, bookend([]byte(encodeULID(u)), '"', '"'), k, spec.KeySeparator)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			u.SetVersion(uuidV7)
+			u.SetVariant(uuid.VariantRFC4122)
 
 		case 3, 5:
 			// choose the correct UUID function
@@ -65,16 +108,36 @@ func UUID(spec *Config, data []byte) ([]byte, error) {
 
 			// loop over the names field
 			for _, field := range nameFields {
-				p, _ := field.(map[string]interface{})["path"].(string)
+				fieldMap, ok := field.(map[string]interface{})
+				if !ok {
+					return nil, SpecError("Spec is invalid. `Names` must be objects with a path and a default")
+				}
+				p, _ := fieldMap["path"].(string)
 
-				name, pathErr := getJSONRaw(data, p, true, spec.KeySeparator)
-				// if a string, remove the heading and trailing quote
-				nameString := strings.TrimPrefix(strings.TrimSuffix(string(name), "\""), "\"")
+				var name []byte
+				var pathErr error
+				if p == "$" {
+					name = data
+				} else {
+					name, pathErr = getJSONRaw(data, p, true, spec.KeySeparator)
+				}
+				if pathErr != nil && pathErr != NonExistentPath {
+					return nil, pathErr
+				}
+				var nameString string
 				if pathErr == NonExistentPath {
-					nameString, ok = field.(map[string]interface{})["default"].(string)
+					defaultValue, ok := fieldMap["default"]
 					if !ok {
 						return nil, SpecError("Spec is invalid. Unable to get path or default")
 					}
+					if nameString, err = canonicalName(defaultValue); err != nil {
+						return nil, SpecError("Spec is invalid. Unable to encode default")
+					}
+				} else {
+					nameString, err = uuidName(name)
+					if err != nil {
+						return nil, err
+					}
 				}
 				u = NewUUID(u, nameString)
 			}
@@ -92,6 +155,90 @@ func UUID(spec *Config, data []byte) ([]byte, error) {
 	return data, nil
 }
 
+// uuidName returns the name of a raw json value for v3 and v5 UUIDs, see
+// canonicalName.
+func uuidName(raw []byte) (string, error) {
+	if len(raw) == 0 {
+		return "", ParseError("Warn: Unable to get UUID name from an empty value")
+	}
+	if raw[0] == '"' {
+		// if a string, remove the heading and trailing quote
+		return strings.TrimPrefix(strings.TrimSuffix(string(raw), "\""), "\""), nil
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return "", err
+	}
+	return canonicalName(decoded)
+}
+
+// canonicalName returns a string as-is, and serializes any other value canonically:
+// numbers in their shortest form, and objects with sorted keys and without
+// whitespace, so that equal values have the same name.
+func canonicalName(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	canonical, err := encodeJSON(normalizeNumbers(v))
+	if err != nil {
+		return "", err
+	}
+	return string(canonical), nil
+}
+
+// newTimeUUID returns a v1 or v6 UUID of the Config's current time, with a random
+// clock sequence and a random node marked as multicast, as allowed by RFC 4122.
+func newTimeUUID(spec *Config, version byte) (uuid.UUID, error) {
+	var u uuid.UUID
+	if _, err := io.ReadFull(spec.random(), u[8:]); err != nil {
+		return u, err
+	}
+	u[10] |= 0x01
+	timestamp := uint64(gregorianOffset + spec.now().UnixNano()/100)
+	if version == uuidV6 {
+		binary.BigEndian.PutUint32(u[0:], uint32(timestamp>>28))
+		binary.BigEndian.PutUint16(u[4:], uint16(timestamp>>12))
+		binary.BigEndian.PutUint16(u[6:], uint16(timestamp&0xfff))
+	} else {
+		binary.BigEndian.PutUint32(u[0:], uint32(timestamp))
+		binary.BigEndian.PutUint16(u[4:], uint16(timestamp>>32))
+		binary.BigEndian.PutUint16(u[6:], uint16(timestamp>>48))
+	}
+	u.SetVersion(version)
+	u.SetVariant(uuid.VariantRFC4122)
+	return u, nil
+}
+
+// newUnixTimeID returns 48 bits of the Config's current unix time in milliseconds
+// followed by 80 random bits, the layout of both v7 UUIDs and ULIDs.
+func newUnixTimeID(spec *Config) (uuid.UUID, error) {
+	var u uuid.UUID
+	if _, err := io.ReadFull(spec.random(), u[6:]); err != nil {
+		return u, err
+	}
+	var millis [8]byte
+	binary.BigEndian.PutUint64(millis[:], uint64(spec.now().UnixNano()/int64(time.Millisecond)))
+	copy(u[:6], millis[2:])
+	return u, nil
+}
+
+// encodeULID encodes the 128 bits of id as the 26 characters of a ULID, five bits per
+// character after two leading zero bits.
+func encodeULID(id uuid.UUID) string {
+	encoded := make([]byte, 26)
+	for i := range encoded {
+		var index byte
+		for bit := i*5 - 2; bit < i*5+3; bit++ {
+			index <<= 1
+			if bit >= 0 {
+				index |= id[bit/8] >> (7 - uint(bit%8)) & 1
+			}
+		}
+		encoded[i] = crockfordBase32[index]
+	}
+	return string(encoded)
+}
+
 func namespaceFromString(namespace string) (uuid.UUID, error) {
 	var u uuid.UUID
 	var err error
@@ -116,9 +263,12 @@ func getUUIDVersion(uuidSpec map[string]interface{}) int {
 	if !ok {
 		return -1
 	}
+	if versionInterface == "ulid" {
+		return ulidVersion
+	}
 	versionFloat, ok := versionInterface.(float64)
 	version = int(versionFloat)
-	if !ok || version < 3 || version > 5 {
+	if !ok || float64(version) != versionFloat || version < 1 || version > 7 || version == 2 {
 		return -2
 	}
 	return version
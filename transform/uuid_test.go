@@ -1,7 +1,9 @@
 package transform
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	uuid "github.com/gofrs/uuid"
 	"github.com/qntfy/jsonparser"
@@ -22,8 +24,31 @@ func TestUUIDV4(t *testing.T) {
 	}
 }
 
+func TestUUIDV4WithRand(t *testing.T) {
+	spec := `{"a.uuid":{"version": 4}}`
+	jsonIn := `{"a":{"author":"jason"}}`
+	jsonOut := `{"a":{"author":"jason","uuid":"00010203-0405-4607-8809-0a0b0c0d0e0f"}}`
+
+	cfg := getConfig(spec, false)
+	cfg.Rand = bytes.NewReader([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+	kazaamOut, err := getTransformTestWrapper(UUID, cfg, jsonIn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(kazaamOut) != jsonOut {
+		t.Error("Transformed data does not match expectation.")
+		t.Log("Expected:   ", jsonOut)
+		t.Log("Actual:     ", string(kazaamOut))
+	}
+
+	// the source of randomness is now exhausted
+	if _, err := getTransformTestWrapper(UUID, cfg, jsonIn); err == nil {
+		t.Error("Should have thrown an error when unable to read random bytes.")
+	}
+}
+
 func TestUUIDVersionError(t *testing.T) {
-	spec := `{"a.uuid":{"version": 6}}`
+	spec := `{"a.uuid":{"version": 8}}`
 	jsonIn := `{"a":{"author":"jason","id":2323223}}`
 
 	cfg := getConfig(spec, false)
@@ -441,6 +466,19 @@ func TestUUIDV5NoNames(t *testing.T) {
 	}
 }
 
+func TestUUIDV5MalformedNamePath(t *testing.T) {
+	spec := `{"id": {"version": 5, "namespace": "DNS", "names": [{"path": "a[x]", "default": "d"}]}}`
+	jsonIn := `{"a":[1]}`
+
+	cfg := getConfig(spec, false)
+	_, err := getTransformTestWrapper(UUID, cfg, jsonIn)
+
+	if err == nil {
+		t.Error("Should have thrown error for a malformed name path")
+		t.FailNow()
+	}
+}
+
 func TestUUIDV5ArrayIndex(t *testing.T) {
 	spec := `{
 		"a.uuid": {
@@ -500,3 +538,98 @@ func TestUUIDWithMultipleSpecs(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestUUIDTimeOrdered(t *testing.T) {
+	testCases := []struct {
+		version string
+		want    string
+	}{
+		{"1", "cabc5000-377d-11ea-8001-030304050607"},
+		{"6", "1ea377dc-abc5-6000-8001-030304050607"},
+		{"7", "016fa8a5-4100-7001-8203-040506070809"},
+		{`"ulid"`, "01DYMAAG80000G40R40M30E209"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			spec := `{"id":{"version": ` + tc.version + `}}`
+			jsonOut := `{"id":"` + tc.want + `"}`
+
+			cfg := getConfig(spec, false)
+			cfg.Clock = func() time.Time { return time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC) }
+			cfg.Rand = bytes.NewReader([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+			kazaamOut, err := getTransformTestWrapper(UUID, cfg, `{}`)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(kazaamOut) != jsonOut {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", jsonOut)
+				t.Log("Actual:     ", string(kazaamOut))
+			}
+		})
+	}
+}
+
+func TestUUIDV1Timestamp(t *testing.T) {
+	now := time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC)
+	cfg := getConfig(`{"id":{"version": 1}}`, false)
+	cfg.Clock = func() time.Time { return now }
+	kazaamOut, err := getTransformTestWrapper(UUID, cfg, `{}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, _, _, _ := jsonparser.Get(kazaamOut, "id")
+	u, err := uuid.FromString(string(out))
+	if err != nil {
+		t.Fatalf("transformed data didn't contain valid UUID: %v", err)
+	}
+	ts, err := uuid.TimestampFromV1(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual, _ := ts.Time(); !actual.Equal(now) {
+		t.Errorf("got time %v; want %v", actual, now)
+	}
+}
+
+func TestUUIDV5WithNonStringNames(t *testing.T) {
+	testCases := []struct {
+		name  string
+		names string
+		in    string
+		want  string
+	}{
+		{"canonical object", `[{"path": "a"}]`, `{"a":{"b": [2.50, "x"], "a": 1}}`, "d72d06c0-4e4f-5090-a612-b12ac3697386"},
+		{"number", `[{"path": "a"}]`, `{"a":42}`, "5c2b23de-4bad-58ee-a4b3-f22f3b9cfd7d"},
+		{"numbers in their shortest form", `[{"path": "a"}]`, `{"a":2.50}`, "636b14fd-7fb8-5ba7-a0af-150236a3c830"},
+		{"number default", `[{"path": "missing", "default": 42}]`, `{}`, "5c2b23de-4bad-58ee-a4b3-f22f3b9cfd7d"},
+		{"object default", `[{"path": "missing", "default": {"b": [2.50, "x"], "a": 1}}]`, `{}`, "d72d06c0-4e4f-5090-a612-b12ac3697386"},
+		{"whole document", `[{"path": "$"}]`, `{"name": "x", "id": 7}`, "442349b0-ae82-5e33-a717-2b0055b355d3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := `{"uuid":{"version": 5, "namespace": "URL", "names": ` + tc.names + `}}`
+			cfg := getConfig(spec, false)
+			kazaamOut, err := getTransformTestWrapper(UUID, cfg, tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			out, _, _, _ := jsonparser.Get(kazaamOut, "uuid")
+			if string(out) != tc.want {
+				t.Error("Transformed data does not match expectation.")
+				t.Log("Expected:   ", tc.want)
+				t.Log("Actual:     ", string(out))
+			}
+		})
+	}
+}
+
+func TestUUIDInvalidNames(t *testing.T) {
+	spec := `{"uuid":{"version": 5, "namespace": "URL", "names": ["a"]}}`
+	cfg := getConfig(spec, false)
+	if _, err := getTransformTestWrapper(UUID, cfg, `{"a":1}`); err == nil {
+		t.Error("Should have thrown an error for a name that is not an object.")
+	}
+}